@@ -1,9 +1,10 @@
 package alias
 
 import (
-	"fmt"
 	"strings"
 	"time"
+
+	"github.com/abtin81badie/GoLangEssentials/mathutils"
 )
 
 // **1. String Alias with Custom Method**
@@ -31,16 +32,11 @@ func (n MyCustomInt) IsEven() bool {
 	return n%2 == 0
 }
 
-// Factorial computes the factorial of MyCustomInt.
+// Factorial computes the factorial of MyCustomInt. Like
+// mathutils.Factorial, it returns an error if n is negative or if the
+// result overflows int.
 func (n MyCustomInt) Factorial() (int, error) {
-	if n < 0 {
-		return 0, fmt.Errorf("factorial not defined for negative numbers")
-	}
-	result := 1
-	for i := 2; i <= int(n); i++ {
-		result *= i
-	}
-	return result, nil
+	return mathutils.Factorial(int(n))
 }
 
 // **3. Function Alias**
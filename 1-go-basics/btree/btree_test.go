@@ -0,0 +1,277 @@
+package btree
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// openTemp opens a new database with small pages, so a few hundred keys
+// are enough to split and merge nodes.
+func openTemp(t *testing.T) (*DB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(path, &Options{PageSize: minPageSize, CacheSize: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, path
+}
+
+func key(i int) []byte { return []byte(fmt.Sprintf("key-%05d", i)) }
+
+// scanAll returns every key in the database in scan order.
+func scanAll(t *testing.T, db *DB) []string {
+	t.Helper()
+	var keys []string
+	if err := db.Scan(nil, nil, func(k, v []byte) bool {
+		keys = append(keys, string(k))
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestPutGetDelete(t *testing.T) {
+	db, _ := openTemp(t)
+	steps := []struct {
+		op         string
+		key, value string
+		want       string
+		found      bool
+	}{
+		{op: "get", key: "a", found: false},
+		{op: "put", key: "a", value: "1"},
+		{op: "get", key: "a", want: "1", found: true},
+		{op: "put", key: "a", value: "2"},
+		{op: "get", key: "a", want: "2", found: true},
+		{op: "put", key: "b", value: ""},
+		{op: "get", key: "b", want: "", found: true},
+		{op: "delete", key: "c", found: false},
+		{op: "delete", key: "a", found: true},
+		{op: "get", key: "a", found: false},
+		{op: "delete", key: "a", found: false},
+		{op: "delete", key: "b", found: true},
+		{op: "get", key: "b", found: false},
+	}
+	for i, s := range steps {
+		switch s.op {
+		case "put":
+			if err := db.Put([]byte(s.key), []byte(s.value)); err != nil {
+				t.Fatalf("step %d: Put: %v", i, err)
+			}
+		case "get":
+			value, found, err := db.Get([]byte(s.key))
+			if err != nil || found != s.found || string(value) != s.want {
+				t.Fatalf("step %d: Get(%q) = %q, %v, %v, want %q, %v", i, s.key, value, found, err, s.want, s.found)
+			}
+		case "delete":
+			found, err := db.Delete([]byte(s.key))
+			if err != nil || found != s.found {
+				t.Fatalf("step %d: Delete(%q) = %v, %v, want %v", i, s.key, found, err, s.found)
+			}
+		}
+	}
+}
+
+func TestSplitMergeAndReopen(t *testing.T) {
+	db, path := openTemp(t)
+	const n = 2000
+	var want []string
+	err := db.Update(func(tx *Tx) error {
+		// Insert in an interleaved order so splits happen all over the tree.
+		for i := 0; i < n; i++ {
+			j := (i * 7919) % n
+			if err := tx.Put(key(j), []byte(fmt.Sprint(j))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		want = append(want, string(key(i)))
+	}
+	if got := scanAll(t, db); !slices.Equal(got, want) {
+		t.Fatalf("scan after insert returned %d keys, want %d in order", len(got), len(want))
+	}
+
+	// Delete every key but each tenth, one transaction per key.
+	for i := 0; i < n; i++ {
+		if i%10 == 0 {
+			continue
+		}
+		if found, err := db.Delete(key(i)); err != nil || !found {
+			t.Fatalf("Delete(%s) = %v, %v", key(i), found, err)
+		}
+	}
+	want = want[:0]
+	for i := 0; i < n; i += 10 {
+		want = append(want, string(key(i)))
+	}
+	if got := scanAll(t, db); !slices.Equal(got, want) {
+		t.Fatalf("scan after delete = %v, want %v", got, want)
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	db, err = Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if got := scanAll(t, db); !slices.Equal(got, want) {
+		t.Fatalf("scan after reopen = %v, want %v", got, want)
+	}
+	value, found, err := db.Get(key(1230))
+	if err != nil || !found || string(value) != "1230" {
+		t.Fatalf("Get after reopen = %q, %v, %v", value, found, err)
+	}
+}
+
+func TestScanRange(t *testing.T) {
+	db, _ := openTemp(t)
+	for i := 0; i < 50; i++ {
+		if err := db.Put(key(i), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		start, end []byte
+		first      int
+		count      int
+	}{
+		{nil, nil, 0, 50},
+		{key(10), key(20), 10, 10},
+		{[]byte("key-00010x"), key(20), 11, 9},
+		{key(45), nil, 45, 5},
+		{nil, key(3), 0, 3},
+		{key(20), key(20), 0, 0},
+		{[]byte("z"), nil, 0, 0},
+	}
+	for _, tt := range tests {
+		var got []string
+		if err := db.Scan(tt.start, tt.end, func(k, v []byte) bool {
+			got = append(got, string(k))
+			return true
+		}); err != nil {
+			t.Fatal(err)
+		}
+		var want []string
+		for i := tt.first; i < tt.first+tt.count; i++ {
+			want = append(want, string(key(i)))
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("Scan(%q, %q) = %v, want %v", tt.start, tt.end, got, want)
+		}
+	}
+}
+
+func TestUpdateRollback(t *testing.T) {
+	db, _ := openTemp(t)
+	if err := db.Put([]byte("kept"), []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	errAbort := errors.New("abort")
+	err := db.Update(func(tx *Tx) error {
+		if err := tx.Put([]byte("dropped"), []byte("2")); err != nil {
+			return err
+		}
+		if _, err := tx.Delete([]byte("kept")); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Update = %v, want errAbort", err)
+	}
+	if got := scanAll(t, db); !slices.Equal(got, []string{"kept"}) {
+		t.Fatalf("keys after rollback = %v, want [kept]", got)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	db, _ := openTemp(t)
+	if err := db.Put(nil, []byte("v")); !errors.Is(err, ErrKeyEmpty) {
+		t.Errorf("Put with empty key = %v, want ErrKeyEmpty", err)
+	}
+	if err := db.Put([]byte("k"), make([]byte, minPageSize)); !errors.Is(err, ErrEntryTooLarge) {
+		t.Errorf("Put of a large value = %v, want ErrEntryTooLarge", err)
+	}
+	err := db.View(func(tx *Tx) error { return tx.Put([]byte("k"), nil) })
+	if !errors.Is(err, ErrTxReadOnly) {
+		t.Errorf("Put in View = %v, want ErrTxReadOnly", err)
+	}
+	var leaked *Tx
+	db.View(func(tx *Tx) error { leaked = tx; return nil })
+	if _, _, err := leaked.Get([]byte("k")); !errors.Is(err, ErrTxClosed) {
+		t.Errorf("Get on a closed Tx = %v, want ErrTxClosed", err)
+	}
+	db.Close()
+	if _, _, err := db.Get([]byte("k")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Get after Close = %v, want ErrClosed", err)
+	}
+}
+
+// TestNoOpWrites checks that deleting an absent key and putting an
+// unchanged value commit nothing: no new transaction, no new pages and
+// no change to the freelist.
+func TestNoOpWrites(t *testing.T) {
+	db, path := openTemp(t)
+	for i := 0; i < 300; i++ {
+		if err := db.Put(key(i), []byte("v")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	txid, free := db.meta.txid, slices.Clone(db.free)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := info.Size()
+
+	for i := 0; i < 50; i++ {
+		if found, err := db.Delete([]byte(fmt.Sprint("absent-", i))); err != nil || found {
+			t.Fatalf("Delete of an absent key = %v, %v", found, err)
+		}
+		if err := db.Put(key(i), []byte("v")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = db.Update(func(tx *Tx) error {
+		if err := tx.Put(key(7), []byte("v")); err != nil {
+			return err
+		}
+		_, err := tx.Delete([]byte("absent"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if db.meta.txid != txid {
+		t.Errorf("no-op writes committed %d transactions", db.meta.txid-txid)
+	}
+	if !slices.Equal(db.free, free) {
+		t.Errorf("no-op writes changed the freelist from %v to %v", free, db.free)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != size {
+		t.Errorf("no-op writes grew the file from %d to %d bytes", size, info.Size())
+	}
+
+	// A real change still commits.
+	if err := db.Put(key(7), []byte("changed")); err != nil {
+		t.Fatal(err)
+	}
+	if db.meta.txid != txid+1 {
+		t.Errorf("changing a value committed %d transactions, want 1", db.meta.txid-txid)
+	}
+}
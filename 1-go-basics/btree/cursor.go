@@ -0,0 +1,175 @@
+package btree
+
+// Cursor iterates over the entries of a transaction in key order.
+// Positioning methods return the key and value at the new position, or
+// nil, nil when the cursor moves past either end or an error occurs; Err
+// reports the error. Returned slices are copies the caller may keep.
+//
+// A Cursor is only valid for the life of its transaction, and entries
+// added or removed after it is positioned may or may not be visited.
+type Cursor struct {
+	tx    *Tx
+	stack []cursorFrame
+	err   error
+}
+
+type cursorFrame struct {
+	n   *node
+	idx int
+}
+
+// Cursor returns a new, unpositioned cursor over the transaction.
+func (tx *Tx) Cursor() *Cursor {
+	return &Cursor{tx: tx}
+}
+
+// Err returns the first error encountered while reading pages.
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// First moves to the smallest key.
+func (c *Cursor) First() ([]byte, []byte) {
+	if !c.reset() {
+		return nil, nil
+	}
+	c.descend(false)
+	return c.settleForward()
+}
+
+// Last moves to the largest key.
+func (c *Cursor) Last() ([]byte, []byte) {
+	if !c.reset() {
+		return nil, nil
+	}
+	c.descend(true)
+	return c.settleBackward()
+}
+
+// Seek moves to the smallest key >= key.
+func (c *Cursor) Seek(key []byte) ([]byte, []byte) {
+	if !c.reset() {
+		return nil, nil
+	}
+	for {
+		top := &c.stack[len(c.stack)-1]
+		if top.n.leaf {
+			top.idx, _ = top.n.search(key)
+			return c.settleForward()
+		}
+		top.idx = top.n.childIndex(key)
+		if !c.push(top.n.children[top.idx]) {
+			return nil, nil
+		}
+	}
+}
+
+// Next moves to the following key.
+func (c *Cursor) Next() ([]byte, []byte) {
+	if len(c.stack) == 0 {
+		return nil, nil
+	}
+	c.stack[len(c.stack)-1].idx++
+	return c.settleForward()
+}
+
+// Prev moves to the preceding key.
+func (c *Cursor) Prev() ([]byte, []byte) {
+	if len(c.stack) == 0 {
+		return nil, nil
+	}
+	c.stack[len(c.stack)-1].idx--
+	return c.settleBackward()
+}
+
+// reset clears the stack and pushes the root. Returns false if the tree
+// is empty or the root cannot be read.
+func (c *Cursor) reset() bool {
+	c.stack = c.stack[:0]
+	if c.err == nil {
+		c.err = c.tx.check(false)
+	}
+	if c.err != nil || c.tx.meta.root == 0 {
+		return false
+	}
+	return c.push(c.tx.meta.root)
+}
+
+func (c *Cursor) push(id pgid) bool {
+	n, err := c.tx.node(id)
+	if err != nil {
+		c.err = err
+		c.stack = c.stack[:0]
+		return false
+	}
+	c.stack = append(c.stack, cursorFrame{n: n})
+	return true
+}
+
+// descend follows the first (or last) child from the top of the stack
+// down to a leaf.
+func (c *Cursor) descend(last bool) bool {
+	for {
+		top := &c.stack[len(c.stack)-1]
+		if last {
+			top.idx = len(top.n.keys) - 1
+		} else {
+			top.idx = 0
+		}
+		if top.n.leaf {
+			return true
+		}
+		if !c.push(top.n.children[top.idx]) {
+			return false
+		}
+	}
+}
+
+// settleForward moves to the next leaf while the leaf index is past the
+// end and returns the entry under the cursor.
+func (c *Cursor) settleForward() ([]byte, []byte) {
+	for len(c.stack) > 0 {
+		top := c.stack[len(c.stack)-1]
+		if top.idx < len(top.n.keys) {
+			if top.n.leaf {
+				return c.entry()
+			}
+			if !c.push(top.n.children[top.idx]) || !c.descend(false) {
+				return nil, nil
+			}
+			continue
+		}
+		c.stack = c.stack[:len(c.stack)-1]
+		if len(c.stack) > 0 {
+			c.stack[len(c.stack)-1].idx++
+		}
+	}
+	return nil, nil
+}
+
+// settleBackward moves to the previous leaf while the leaf index is
+// before the start and returns the entry under the cursor.
+func (c *Cursor) settleBackward() ([]byte, []byte) {
+	for len(c.stack) > 0 {
+		top := c.stack[len(c.stack)-1]
+		if top.idx >= 0 && top.idx < len(top.n.keys) {
+			if top.n.leaf {
+				return c.entry()
+			}
+			if !c.push(top.n.children[top.idx]) || !c.descend(true) {
+				return nil, nil
+			}
+			continue
+		}
+		c.stack = c.stack[:len(c.stack)-1]
+		if len(c.stack) > 0 {
+			c.stack[len(c.stack)-1].idx--
+		}
+	}
+	return nil, nil
+}
+
+func (c *Cursor) entry() ([]byte, []byte) {
+	top := c.stack[len(c.stack)-1]
+	return append([]byte(nil), top.n.keys[top.idx]...), append([]byte{}, top.n.vals[top.idx]...)
+}
@@ -0,0 +1,298 @@
+// Package btree implements a persistent B+tree stored in a single file.
+//
+// The file is divided into fixed-size pages. Keys and values live in leaf
+// pages, branch pages hold separator keys, and two meta pages at the start
+// of the file point at the current root. Writes are copy-on-write: a
+// transaction never overwrites a page the last commit can reach. It writes
+// new pages, syncs them, and only then writes the alternate meta page, so
+// a crash at any point leaves the previous commit intact. Pages replaced
+// by a commit are recorded in an on-disk freelist and reused by the next
+// one. Recently read pages are kept decoded in an LRU page cache.
+//
+// A DB allows many concurrent readers (View) or a single writer (Update).
+package btree
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/abtin81badie/GoLangEssentials/cache"
+)
+
+// Errors returned by the btree package.
+var (
+	ErrClosed        = errors.New("btree: database is closed")
+	ErrCorrupt       = errors.New("btree: file is corrupt")
+	ErrKeyEmpty      = errors.New("btree: key must not be empty")
+	ErrEntryTooLarge = errors.New("btree: key and value are too large for a page")
+	ErrTxReadOnly    = errors.New("btree: transaction is read-only")
+	ErrTxClosed      = errors.New("btree: transaction is closed")
+)
+
+const (
+	// DefaultPageSize is the page size used when Options.PageSize is zero.
+	DefaultPageSize = 4096
+	// DefaultCacheSize is the number of pages cached when Options.CacheSize is zero.
+	DefaultCacheSize = 256
+
+	minPageSize = 512
+)
+
+// Options configures Open.
+type Options struct {
+	// PageSize is the size of each page in bytes for a new file. Existing
+	// files keep the page size they were created with.
+	PageSize int
+	// CacheSize is the number of decoded pages kept in memory.
+	CacheSize int
+}
+
+// DB is a B+tree stored in a single file.
+type DB struct {
+	mu       sync.RWMutex
+	file     *os.File
+	pageSize int
+	meta     meta
+	free     []pgid // pages free for the next transaction
+	flPages  []pgid // pages holding the current freelist
+	pages    cache.Cache[pgid, *node]
+	closed   bool
+}
+
+// Open opens the database file at path, creating it if it does not exist.
+// opts may be nil.
+func Open(path string, opts *Options) (*DB, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	pageSize := opts.PageSize
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize < minPageSize || pageSize > 1<<16 {
+		return nil, fmt.Errorf("btree: page size %d out of range [%d, %d]", pageSize, minPageSize, 1<<16)
+	}
+	cacheSize := opts.CacheSize
+	if cacheSize == 0 {
+		cacheSize = DefaultCacheSize
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	db := &DB{
+		file:  f,
+		pages: cache.New(cache.LRU, cache.Options[pgid, *node]{Capacity: cacheSize, Synchronized: true}),
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() == 0 {
+		err = db.init(pageSize)
+	} else {
+		err = db.load(pageSize)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// init writes both meta pages of a new, empty database.
+func (db *DB) init(pageSize int) error {
+	db.pageSize = pageSize
+	db.meta = meta{pageSize: uint32(pageSize), pageCount: metaPageCount}
+	for id := pgid(0); id < metaPageCount; id++ {
+		if err := db.writeMeta(db.meta, id); err != nil {
+			return err
+		}
+	}
+	return db.file.Sync()
+}
+
+// load reads the newest valid meta page and the freelist it points to.
+// The page size is taken from meta page 0; if that page is damaged the
+// configured page size is used to find meta page 1.
+func (db *DB) load(pageSize int) error {
+	buf := make([]byte, metaSize)
+	var metas []meta
+	if _, err := db.file.ReadAt(buf, 0); err == nil {
+		if m, err := decodeMeta(buf); err == nil {
+			metas = append(metas, m)
+			pageSize = int(m.pageSize)
+		}
+	}
+	if _, err := db.file.ReadAt(buf, int64(pageSize)); err == nil {
+		if m, err := decodeMeta(buf); err == nil && int(m.pageSize) == pageSize {
+			metas = append(metas, m)
+		}
+	}
+	if len(metas) == 0 {
+		return fmt.Errorf("%w: no valid meta page", ErrCorrupt)
+	}
+	db.pageSize = pageSize
+	db.meta = metas[0]
+	for _, m := range metas[1:] {
+		if m.txid > db.meta.txid {
+			db.meta = m
+		}
+	}
+	free, flPages, err := db.readFreelist(db.meta.freelist)
+	if err != nil {
+		return err
+	}
+	db.free, db.flPages = free, flPages
+	return nil
+}
+
+func (db *DB) readPage(id pgid) ([]byte, error) {
+	if id < metaPageCount || uint64(id) >= db.meta.pageCount {
+		return nil, fmt.Errorf("%w: page %d out of range", ErrCorrupt, id)
+	}
+	buf := make([]byte, db.pageSize)
+	if _, err := db.file.ReadAt(buf, int64(id)*int64(db.pageSize)); err != nil {
+		return nil, fmt.Errorf("btree: read page %d: %w", id, err)
+	}
+	return buf, nil
+}
+
+func (db *DB) writePage(id pgid, buf []byte) error {
+	if _, err := db.file.WriteAt(buf, int64(id)*int64(db.pageSize)); err != nil {
+		return fmt.Errorf("btree: write page %d: %w", id, err)
+	}
+	return nil
+}
+
+func (db *DB) writeMeta(m meta, id pgid) error {
+	buf := make([]byte, db.pageSize)
+	m.encode(buf)
+	return db.writePage(id, buf)
+}
+
+// readNode returns the decoded node stored on page id, using the page cache.
+func (db *DB) readNode(id pgid) (*node, error) {
+	if n, ok := db.pages.Get(id); ok {
+		return n, nil
+	}
+	buf, err := db.readPage(id)
+	if err != nil {
+		return nil, err
+	}
+	n, err := decodeNode(id, buf)
+	if err != nil {
+		return nil, err
+	}
+	db.pages.Set(id, n)
+	return n, nil
+}
+
+// readFreelist follows the freelist chain starting at head.
+func (db *DB) readFreelist(head pgid) (free, pages []pgid, err error) {
+	for id := head; id != 0; {
+		if len(pages) > int(db.meta.pageCount) {
+			return nil, nil, fmt.Errorf("%w: freelist loops", ErrCorrupt)
+		}
+		buf, err := db.readPage(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids, next, err := decodeFreelist(id, buf)
+		if err != nil {
+			return nil, nil, err
+		}
+		pages = append(pages, id)
+		free = append(free, ids...)
+		id = next
+	}
+	return free, pages, nil
+}
+
+// maxEntrySize returns the largest encoded leaf entry allowed, chosen so
+// that any page holds at least four entries and a split always fits.
+func (db *DB) maxEntrySize() int {
+	return (db.pageSize - pageHeaderSize) / 4
+}
+
+// Close closes the database file. Committed data is already durable.
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return nil
+	}
+	db.closed = true
+	return db.file.Close()
+}
+
+// View runs fn in a read-only transaction. Several View calls may run
+// at once, but they wait for a running Update to finish.
+func (db *DB) View(fn func(tx *Tx) error) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.closed {
+		return ErrClosed
+	}
+	tx := db.begin(false)
+	defer tx.close()
+	return fn(tx)
+}
+
+// Update runs fn in a read-write transaction and commits it if fn returns
+// nil. If fn returns an error, every change made by fn is discarded.
+// Only one Update runs at a time.
+func (db *DB) Update(fn func(tx *Tx) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return ErrClosed
+	}
+	tx := db.begin(true)
+	defer tx.close()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.commit()
+}
+
+// Get returns a copy of the value stored under key, or false if absent.
+func (db *DB) Get(key []byte) ([]byte, bool, error) {
+	var value []byte
+	var found bool
+	err := db.View(func(tx *Tx) error {
+		var err error
+		value, found, err = tx.Get(key)
+		return err
+	})
+	return value, found, err
+}
+
+// Put stores value under key in its own transaction.
+func (db *DB) Put(key, value []byte) error {
+	return db.Update(func(tx *Tx) error { return tx.Put(key, value) })
+}
+
+// Delete removes key in its own transaction. Returns false if it was absent.
+func (db *DB) Delete(key []byte) (bool, error) {
+	var deleted bool
+	err := db.Update(func(tx *Tx) error {
+		var err error
+		deleted, err = tx.Delete(key)
+		return err
+	})
+	return deleted, err
+}
+
+// Scan calls fn in ascending key order for every entry with
+// start <= key < end. A nil start or end leaves that side unbounded.
+// Iteration stops early if fn returns false.
+func (db *DB) Scan(start, end []byte, fn func(key, value []byte) bool) error {
+	return db.View(func(tx *Tx) error {
+		return tx.Scan(start, end, fn)
+	})
+}
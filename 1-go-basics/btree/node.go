@@ -0,0 +1,129 @@
+package btree
+
+import (
+	"bytes"
+	"sort"
+)
+
+// node is the decoded form of a leaf or branch page. In a branch,
+// keys[i] is the smallest key reachable through children[i]; lookups
+// follow the last child whose key is <= the search key. keys[0] only
+// acts as a lower bound and may be stale after deletes.
+//
+// Nodes decoded from disk are shared through the page cache and must
+// not be modified; a transaction clones a node before changing it.
+type node struct {
+	leaf     bool
+	keys     [][]byte
+	vals     [][]byte // leaf only
+	children []pgid   // branch only
+}
+
+// size returns the number of bytes n occupies when encoded.
+func (n *node) size() int {
+	sz := pageHeaderSize
+	for i, k := range n.keys {
+		if n.leaf {
+			sz += leafEntryHeader + len(k) + len(n.vals[i])
+		} else {
+			sz += branchEntryHeader + len(k)
+		}
+	}
+	return sz
+}
+
+// clone returns a copy of n whose slices can be modified independently.
+// Key and value byte slices are shared since they are never modified in place.
+func (n *node) clone() *node {
+	c := &node{leaf: n.leaf, keys: append([][]byte(nil), n.keys...)}
+	if n.leaf {
+		c.vals = append([][]byte(nil), n.vals...)
+	} else {
+		c.children = append([]pgid(nil), n.children...)
+	}
+	return c
+}
+
+// search returns the index of the first key >= key in a leaf and whether
+// it is an exact match.
+func (n *node) search(key []byte) (int, bool) {
+	i := sort.Search(len(n.keys), func(i int) bool { return bytes.Compare(n.keys[i], key) >= 0 })
+	return i, i < len(n.keys) && bytes.Equal(n.keys[i], key)
+}
+
+// childIndex returns the index of the child of a branch to descend into for key.
+func (n *node) childIndex(key []byte) int {
+	i := sort.Search(len(n.keys), func(i int) bool { return bytes.Compare(n.keys[i], key) > 0 })
+	if i > 0 {
+		i--
+	}
+	return i
+}
+
+// insertAt inserts a leaf entry at position i.
+func (n *node) insertAt(i int, key, value []byte) {
+	n.keys = append(n.keys, nil)
+	copy(n.keys[i+1:], n.keys[i:])
+	n.keys[i] = key
+	n.vals = append(n.vals, nil)
+	copy(n.vals[i+1:], n.vals[i:])
+	n.vals[i] = value
+}
+
+// insertChild inserts a branch entry at position i.
+func (n *node) insertChild(i int, key []byte, child pgid) {
+	n.keys = append(n.keys, nil)
+	copy(n.keys[i+1:], n.keys[i:])
+	n.keys[i] = key
+	n.children = append(n.children, 0)
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = child
+}
+
+// removeAt removes entry i from a leaf or branch.
+func (n *node) removeAt(i int) {
+	n.keys = append(n.keys[:i], n.keys[i+1:]...)
+	if n.leaf {
+		n.vals = append(n.vals[:i], n.vals[i+1:]...)
+	} else {
+		n.children = append(n.children[:i], n.children[i+1:]...)
+	}
+}
+
+// split moves the upper half of n, by encoded size, into a new node and
+// returns it. Both halves keep at least one entry.
+func (n *node) split() *node {
+	half := n.size() / 2
+	sz := pageHeaderSize
+	at := 1
+	for ; at < len(n.keys)-1; at++ {
+		if n.leaf {
+			sz += leafEntryHeader + len(n.keys[at-1]) + len(n.vals[at-1])
+		} else {
+			sz += branchEntryHeader + len(n.keys[at-1])
+		}
+		if sz >= half {
+			break
+		}
+	}
+	right := &node{leaf: n.leaf, keys: append([][]byte(nil), n.keys[at:]...)}
+	n.keys = n.keys[:at:at]
+	if n.leaf {
+		right.vals = append([][]byte(nil), n.vals[at:]...)
+		n.vals = n.vals[:at:at]
+	} else {
+		right.children = append([]pgid(nil), n.children[at:]...)
+		n.children = n.children[:at:at]
+	}
+	return right
+}
+
+// merge appends every entry of right to n.
+func (n *node) merge(right *node) {
+	n.keys = append(n.keys, right.keys...)
+	if n.leaf {
+		n.vals = append(n.vals, right.vals...)
+	} else {
+		n.children = append(n.children, right.children...)
+	}
+}
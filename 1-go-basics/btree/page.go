@@ -0,0 +1,225 @@
+package btree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// pgid identifies a page by its position in the file.
+type pgid uint64
+
+const (
+	// Pages 0 and 1 hold the two alternating meta pages.
+	metaPageCount = 2
+
+	// tempPgidBase marks ids handed to nodes created during a transaction.
+	// They are replaced with real page ids when the transaction commits.
+	tempPgidBase pgid = 1 << 63
+)
+
+// Page types, stored in the first byte of every non-meta page.
+const (
+	pageLeaf     byte = 1
+	pageBranch   byte = 2
+	pageFreelist byte = 3
+)
+
+// Page layout:
+//
+//	header:   type (1) | unused (1) | count (2) | crc32 (4)
+//	leaf:     count x [klen (2) | vlen (2) | key | value]
+//	branch:   count x [child (8) | klen (2) | key]
+//	freelist: next (8) | count x [pgid (8)]
+const (
+	pageHeaderSize    = 8
+	leafEntryHeader   = 4
+	branchEntryHeader = 10
+	freelistHeader    = 8
+)
+
+// meta is the root record of the file. Two copies are kept on pages 0
+// and 1 and written alternately, so a torn write of one leaves the other
+// intact; the valid copy with the highest txid wins on open.
+type meta struct {
+	pageSize  uint32
+	txid      uint64
+	root      pgid // 0 when the tree is empty
+	freelist  pgid // first freelist page, 0 when there are no free pages
+	pageCount uint64
+}
+
+const (
+	metaMagic   = "GOBPTREE"
+	metaVersion = 1
+	metaSize    = 8 + 4 + 4 + 8 + 8 + 8 + 8 + 4
+)
+
+func (m *meta) encode(buf []byte) {
+	copy(buf[0:8], metaMagic)
+	binary.LittleEndian.PutUint32(buf[8:], metaVersion)
+	binary.LittleEndian.PutUint32(buf[12:], m.pageSize)
+	binary.LittleEndian.PutUint64(buf[16:], m.txid)
+	binary.LittleEndian.PutUint64(buf[24:], uint64(m.root))
+	binary.LittleEndian.PutUint64(buf[32:], uint64(m.freelist))
+	binary.LittleEndian.PutUint64(buf[40:], m.pageCount)
+	binary.LittleEndian.PutUint32(buf[48:], crc32.ChecksumIEEE(buf[:48]))
+}
+
+func decodeMeta(buf []byte) (meta, error) {
+	if len(buf) < metaSize || string(buf[0:8]) != metaMagic {
+		return meta{}, fmt.Errorf("%w: bad meta magic", ErrCorrupt)
+	}
+	if crc32.ChecksumIEEE(buf[:48]) != binary.LittleEndian.Uint32(buf[48:]) {
+		return meta{}, fmt.Errorf("%w: meta checksum mismatch", ErrCorrupt)
+	}
+	if v := binary.LittleEndian.Uint32(buf[8:]); v != metaVersion {
+		return meta{}, fmt.Errorf("%w: unsupported version %d", ErrCorrupt, v)
+	}
+	return meta{
+		pageSize:  binary.LittleEndian.Uint32(buf[12:]),
+		txid:      binary.LittleEndian.Uint64(buf[16:]),
+		root:      pgid(binary.LittleEndian.Uint64(buf[24:])),
+		freelist:  pgid(binary.LittleEndian.Uint64(buf[32:])),
+		pageCount: binary.LittleEndian.Uint64(buf[40:]),
+	}, nil
+}
+
+// sealPage stores the checksum of a fully written page in its header.
+func sealPage(buf []byte) {
+	binary.LittleEndian.PutUint32(buf[4:], 0)
+	binary.LittleEndian.PutUint32(buf[4:], crc32.ChecksumIEEE(buf))
+}
+
+// checkPage verifies the checksum written by sealPage.
+func checkPage(id pgid, buf []byte) error {
+	want := binary.LittleEndian.Uint32(buf[4:])
+	binary.LittleEndian.PutUint32(buf[4:], 0)
+	got := crc32.ChecksumIEEE(buf)
+	binary.LittleEndian.PutUint32(buf[4:], want)
+	if got != want {
+		return fmt.Errorf("%w: page %d checksum mismatch", ErrCorrupt, id)
+	}
+	return nil
+}
+
+// encodeNode writes n into buf, which must be a zeroed page.
+func encodeNode(n *node, buf []byte) {
+	if n.leaf {
+		buf[0] = pageLeaf
+	} else {
+		buf[0] = pageBranch
+	}
+	binary.LittleEndian.PutUint16(buf[2:], uint16(len(n.keys)))
+	off := pageHeaderSize
+	for i, k := range n.keys {
+		if n.leaf {
+			v := n.vals[i]
+			binary.LittleEndian.PutUint16(buf[off:], uint16(len(k)))
+			binary.LittleEndian.PutUint16(buf[off+2:], uint16(len(v)))
+			off += leafEntryHeader
+			off += copy(buf[off:], k)
+			off += copy(buf[off:], v)
+		} else {
+			binary.LittleEndian.PutUint64(buf[off:], uint64(n.children[i]))
+			binary.LittleEndian.PutUint16(buf[off+8:], uint16(len(k)))
+			off += branchEntryHeader
+			off += copy(buf[off:], k)
+		}
+	}
+	sealPage(buf)
+}
+
+// decodeNode parses a leaf or branch page. Keys and values are copied out
+// of buf so the page buffer can be reused.
+func decodeNode(id pgid, buf []byte) (*node, error) {
+	if err := checkPage(id, buf); err != nil {
+		return nil, err
+	}
+	n := &node{}
+	switch buf[0] {
+	case pageLeaf:
+		n.leaf = true
+	case pageBranch:
+	default:
+		return nil, fmt.Errorf("%w: page %d has type %d, want leaf or branch", ErrCorrupt, id, buf[0])
+	}
+	count := int(binary.LittleEndian.Uint16(buf[2:]))
+	n.keys = make([][]byte, count)
+	if n.leaf {
+		n.vals = make([][]byte, count)
+	} else {
+		n.children = make([]pgid, count)
+	}
+	off := pageHeaderSize
+	for i := 0; i < count; i++ {
+		if n.leaf {
+			if off+leafEntryHeader > len(buf) {
+				return nil, fmt.Errorf("%w: page %d entry %d overflows", ErrCorrupt, id, i)
+			}
+			klen := int(binary.LittleEndian.Uint16(buf[off:]))
+			vlen := int(binary.LittleEndian.Uint16(buf[off+2:]))
+			off += leafEntryHeader
+			if off+klen+vlen > len(buf) {
+				return nil, fmt.Errorf("%w: page %d entry %d overflows", ErrCorrupt, id, i)
+			}
+			n.keys[i] = append([]byte(nil), buf[off:off+klen]...)
+			off += klen
+			n.vals[i] = append([]byte(nil), buf[off:off+vlen]...)
+			off += vlen
+		} else {
+			if off+branchEntryHeader > len(buf) {
+				return nil, fmt.Errorf("%w: page %d entry %d overflows", ErrCorrupt, id, i)
+			}
+			n.children[i] = pgid(binary.LittleEndian.Uint64(buf[off:]))
+			klen := int(binary.LittleEndian.Uint16(buf[off+8:]))
+			off += branchEntryHeader
+			if off+klen > len(buf) {
+				return nil, fmt.Errorf("%w: page %d entry %d overflows", ErrCorrupt, id, i)
+			}
+			n.keys[i] = append([]byte(nil), buf[off:off+klen]...)
+			off += klen
+		}
+	}
+	return n, nil
+}
+
+// freelistCapacity returns how many page ids fit on one freelist page.
+func freelistCapacity(pageSize int) int {
+	return (pageSize - pageHeaderSize - freelistHeader) / 8
+}
+
+// encodeFreelist writes ids and a link to the next freelist page into buf.
+func encodeFreelist(ids []pgid, next pgid, buf []byte) {
+	buf[0] = pageFreelist
+	binary.LittleEndian.PutUint16(buf[2:], uint16(len(ids)))
+	binary.LittleEndian.PutUint64(buf[pageHeaderSize:], uint64(next))
+	off := pageHeaderSize + freelistHeader
+	for _, id := range ids {
+		binary.LittleEndian.PutUint64(buf[off:], uint64(id))
+		off += 8
+	}
+	sealPage(buf)
+}
+
+// decodeFreelist parses a freelist page, returning its ids and the next page.
+func decodeFreelist(id pgid, buf []byte) ([]pgid, pgid, error) {
+	if err := checkPage(id, buf); err != nil {
+		return nil, 0, err
+	}
+	if buf[0] != pageFreelist {
+		return nil, 0, fmt.Errorf("%w: page %d has type %d, want freelist", ErrCorrupt, id, buf[0])
+	}
+	count := int(binary.LittleEndian.Uint16(buf[2:]))
+	if count > freelistCapacity(len(buf)) {
+		return nil, 0, fmt.Errorf("%w: freelist page %d holds %d ids", ErrCorrupt, id, count)
+	}
+	next := pgid(binary.LittleEndian.Uint64(buf[pageHeaderSize:]))
+	ids := make([]pgid, count)
+	off := pageHeaderSize + freelistHeader
+	for i := range ids {
+		ids[i] = pgid(binary.LittleEndian.Uint64(buf[off:]))
+		off += 8
+	}
+	return ids, next, nil
+}
@@ -0,0 +1,434 @@
+package btree
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Tx is a transaction. Read-only transactions come from DB.View and
+// read-write ones from DB.Update. A Tx must not be used after the
+// function it was passed to returns.
+type Tx struct {
+	db       *DB
+	writable bool
+	closed   bool
+	meta     meta
+
+	// The fields below are only used by read-write transactions.
+	dirty     map[pgid]*node // nodes changed in this transaction
+	freed     []pgid         // committed pages replaced by this transaction
+	available []pgid         // free pages this transaction may allocate
+	nextTemp  pgid
+	written   map[pgid]*node // pages written by commit, cached once it succeeds
+}
+
+// frame is one step of a root-to-leaf path: a branch and the index of
+// the child that was followed.
+type frame struct {
+	id  pgid
+	idx int
+}
+
+func (db *DB) begin(writable bool) *Tx {
+	tx := &Tx{db: db, writable: writable, meta: db.meta}
+	if writable {
+		tx.dirty = make(map[pgid]*node)
+		tx.available = append([]pgid(nil), db.free...)
+		tx.nextTemp = tempPgidBase
+	}
+	return tx
+}
+
+func (tx *Tx) close() {
+	tx.closed = true
+	tx.dirty = nil
+}
+
+// node returns the current version of page id as seen by this transaction.
+func (tx *Tx) node(id pgid) (*node, error) {
+	if n, ok := tx.dirty[id]; ok {
+		return n, nil
+	}
+	if id >= tempPgidBase {
+		return nil, fmt.Errorf("%w: dangling temporary page %d", ErrCorrupt, id)
+	}
+	return tx.db.readNode(id)
+}
+
+// writableNode returns a private copy of page id that this transaction
+// may modify. The copy keeps the id until commit assigns a new page.
+func (tx *Tx) writableNode(id pgid) (*node, error) {
+	if n, ok := tx.dirty[id]; ok {
+		return n, nil
+	}
+	n, err := tx.db.readNode(id)
+	if err != nil {
+		return nil, err
+	}
+	n = n.clone()
+	tx.dirty[id] = n
+	return n, nil
+}
+
+// newNode registers a node created in this transaction under a temporary id.
+func (tx *Tx) newNode(n *node) pgid {
+	id := tx.nextTemp
+	tx.nextTemp++
+	tx.dirty[id] = n
+	return id
+}
+
+// release drops a node that is no longer part of the tree.
+func (tx *Tx) release(id pgid) {
+	delete(tx.dirty, id)
+	if id < tempPgidBase {
+		tx.freed = append(tx.freed, id)
+	}
+}
+
+func (tx *Tx) check(write bool) error {
+	if tx.closed {
+		return ErrTxClosed
+	}
+	if write && !tx.writable {
+		return ErrTxReadOnly
+	}
+	return nil
+}
+
+// Get returns a copy of the value stored under key, or false if absent.
+func (tx *Tx) Get(key []byte) ([]byte, bool, error) {
+	if err := tx.check(false); err != nil {
+		return nil, false, err
+	}
+	leaf, i, found, err := tx.lookup(key)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	return append([]byte(nil), leaf.vals[i]...), true, nil
+}
+
+// lookup finds the leaf for key without making anything writable, and
+// returns it with the position of key in it. The leaf is nil if the
+// tree is empty.
+func (tx *Tx) lookup(key []byte) (*node, int, bool, error) {
+	if tx.meta.root == 0 {
+		return nil, 0, false, nil
+	}
+	n, err := tx.node(tx.meta.root)
+	for err == nil && !n.leaf {
+		n, err = tx.node(n.children[n.childIndex(key)])
+	}
+	if err != nil {
+		return nil, 0, false, err
+	}
+	i, found := n.search(key)
+	return n, i, found, nil
+}
+
+// descend makes every node on the path to the leaf for key writable and
+// returns the path of branches and the leaf id.
+func (tx *Tx) descend(key []byte) ([]frame, pgid, *node, error) {
+	var path []frame
+	id := tx.meta.root
+	for {
+		n, err := tx.writableNode(id)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if n.leaf {
+			return path, id, n, nil
+		}
+		i := n.childIndex(key)
+		path = append(path, frame{id: id, idx: i})
+		id = n.children[i]
+	}
+}
+
+// Put stores value under key, replacing any existing value. The key and
+// value are copied. Storing the value a key already has changes nothing,
+// so it writes no pages at commit.
+func (tx *Tx) Put(key, value []byte) error {
+	if err := tx.check(true); err != nil {
+		return err
+	}
+	if len(key) == 0 {
+		return ErrKeyEmpty
+	}
+	if leafEntryHeader+len(key)+len(value) > tx.db.maxEntrySize() {
+		return fmt.Errorf("%w: %d bytes, limit %d", ErrEntryTooLarge,
+			leafEntryHeader+len(key)+len(value), tx.db.maxEntrySize())
+	}
+	// Check read-only first, so that a no-op does not copy the path.
+	leaf, i, found, err := tx.lookup(key)
+	if err != nil {
+		return err
+	}
+	if found && bytes.Equal(leaf.vals[i], value) {
+		return nil
+	}
+	key = append([]byte(nil), key...)
+	value = append([]byte(nil), value...)
+
+	if tx.meta.root == 0 {
+		tx.meta.root = tx.newNode(&node{leaf: true, keys: [][]byte{key}, vals: [][]byte{value}})
+		return nil
+	}
+	path, id, leaf, err := tx.descend(key)
+	if err != nil {
+		return err
+	}
+	if i, found := leaf.search(key); found {
+		leaf.vals[i] = value
+	} else {
+		leaf.insertAt(i, key, value)
+	}
+	tx.splitUp(path, id, leaf)
+	return nil
+}
+
+// splitUp splits n while it is larger than a page, inserting each new
+// right half into the parent and growing a new root when needed.
+func (tx *Tx) splitUp(path []frame, id pgid, n *node) {
+	for n.size() > tx.db.pageSize {
+		right := n.split()
+		rightID := tx.newNode(right)
+		if len(path) == 0 {
+			root := &node{keys: [][]byte{n.keys[0], right.keys[0]}, children: []pgid{id, rightID}}
+			tx.meta.root = tx.newNode(root)
+			return
+		}
+		f := path[len(path)-1]
+		path = path[:len(path)-1]
+		parent := tx.dirty[f.id]
+		parent.insertChild(f.idx+1, right.keys[0], rightID)
+		id, n = f.id, parent
+	}
+}
+
+// Delete removes key. Returns false if it was absent, in which case
+// nothing is copied or written.
+func (tx *Tx) Delete(key []byte) (bool, error) {
+	if err := tx.check(true); err != nil {
+		return false, err
+	}
+	if _, _, found, err := tx.lookup(key); err != nil || !found {
+		return false, err
+	}
+	path, id, leaf, err := tx.descend(key)
+	if err != nil {
+		return false, err
+	}
+	i, _ := leaf.search(key)
+	leaf.removeAt(i)
+	return true, tx.rebalance(path, id, leaf)
+}
+
+// rebalance walks up from a node that lost an entry. Empty nodes are
+// removed from their parent and nodes under a quarter full are merged
+// with a sibling when the result fits in a page. Finally the root is
+// collapsed while it is a branch with a single child.
+func (tx *Tx) rebalance(path []frame, id pgid, n *node) error {
+	for len(path) > 0 {
+		if len(n.keys) > 0 && n.size() >= tx.db.pageSize/4 {
+			break
+		}
+		f := path[len(path)-1]
+		path = path[:len(path)-1]
+		parent := tx.dirty[f.id]
+		if len(n.keys) == 0 {
+			parent.removeAt(f.idx)
+			tx.release(id)
+		} else if merged, err := tx.mergeWithSibling(parent, f.idx, n); err != nil {
+			return err
+		} else if !merged {
+			break
+		}
+		id, n = f.id, parent
+	}
+
+	for tx.meta.root != 0 {
+		root, err := tx.node(tx.meta.root)
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(root.keys) == 0:
+			tx.release(tx.meta.root)
+			tx.meta.root = 0
+		case !root.leaf && len(root.children) == 1:
+			child := root.children[0]
+			tx.release(tx.meta.root)
+			tx.meta.root = child
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// mergeWithSibling merges child idx of parent, n, with its left sibling
+// (or right sibling for the first child) if both fit in one page.
+func (tx *Tx) mergeWithSibling(parent *node, idx int, n *node) (bool, error) {
+	if len(parent.children) < 2 {
+		return false, nil
+	}
+	left := idx - 1
+	if idx == 0 {
+		left = 0
+	}
+	sibIdx := left
+	if idx == 0 {
+		sibIdx = 1
+	}
+	sib, err := tx.node(parent.children[sibIdx])
+	if err != nil {
+		return false, err
+	}
+	if n.size()+sib.size()-pageHeaderSize > tx.db.pageSize {
+		return false, nil
+	}
+	leftNode, err := tx.writableNode(parent.children[left])
+	if err != nil {
+		return false, err
+	}
+	rightNode, err := tx.node(parent.children[left+1])
+	if err != nil {
+		return false, err
+	}
+	leftNode.merge(rightNode)
+	tx.release(parent.children[left+1])
+	parent.removeAt(left + 1)
+	return true, nil
+}
+
+// Scan calls fn in ascending key order for every entry with
+// start <= key < end. A nil start or end leaves that side unbounded.
+// Iteration stops early if fn returns false. fn must not modify the tree.
+func (tx *Tx) Scan(start, end []byte, fn func(key, value []byte) bool) error {
+	c := tx.Cursor()
+	var k, v []byte
+	if start == nil {
+		k, v = c.First()
+	} else {
+		k, v = c.Seek(start)
+	}
+	for ; k != nil; k, v = c.Next() {
+		if end != nil && bytes.Compare(k, end) >= 0 {
+			break
+		}
+		if !fn(k, v) {
+			break
+		}
+	}
+	return c.Err()
+}
+
+// allocate returns a page for a node written by commit, reusing free
+// pages before growing the file.
+func (tx *Tx) allocate() pgid {
+	if n := len(tx.available); n > 0 {
+		id := tx.available[n-1]
+		tx.available = tx.available[:n-1]
+		return id
+	}
+	id := pgid(tx.meta.pageCount)
+	tx.meta.pageCount++
+	return id
+}
+
+// spill writes the dirty subtree rooted at id to newly allocated pages
+// and returns the page id of its root.
+func (tx *Tx) spill(id pgid) (pgid, error) {
+	n, ok := tx.dirty[id]
+	if !ok {
+		return id, nil // Unchanged subtree, keep the committed page
+	}
+	if !n.leaf {
+		for i, child := range n.children {
+			newID, err := tx.spill(child)
+			if err != nil {
+				return 0, err
+			}
+			n.children[i] = newID
+		}
+	}
+	newID := tx.allocate()
+	buf := make([]byte, tx.db.pageSize)
+	encodeNode(n, buf)
+	if err := tx.db.writePage(newID, buf); err != nil {
+		return 0, err
+	}
+	if id < tempPgidBase {
+		tx.freed = append(tx.freed, id)
+	}
+	tx.written[newID] = n
+	return newID, nil
+}
+
+// commit makes the transaction durable:
+//  1. write every dirty node to a page no committed tree refers to,
+//  2. write the new freelist, also to pages no committed tree refers to,
+//  3. sync, then write the alternate meta page and sync again.
+//
+// Until step 3 completes the previous meta page remains the newest valid
+// one, so a crash loses this transaction but never corrupts the tree.
+func (tx *Tx) commit() error {
+	if len(tx.dirty) == 0 && len(tx.freed) == 0 && tx.meta.root == tx.db.meta.root {
+		return nil // Nothing changed
+	}
+	db := tx.db
+	tx.written = make(map[pgid]*node)
+	root, err := tx.spill(tx.meta.root)
+	if err != nil {
+		return err
+	}
+	tx.meta.root = root
+
+	// The freelist pages come out of the available pages too. Each one
+	// taken shrinks the list, so reserving for the current length is
+	// enough. Pages freed by this transaction are still referenced by the
+	// current meta page and only become allocatable in the next one.
+	perPage := freelistCapacity(db.pageSize)
+	flCount := (len(tx.available) + len(tx.freed) + len(db.flPages) + perPage - 1) / perPage
+	flPages := make([]pgid, flCount)
+	for i := range flPages {
+		flPages[i] = tx.allocate()
+	}
+	free := append(append(tx.available, tx.freed...), db.flPages...)
+	for i, id := range flPages {
+		chunk := free[min(i*perPage, len(free)):min((i+1)*perPage, len(free))]
+		var next pgid
+		if i+1 < len(flPages) {
+			next = flPages[i+1]
+		}
+		buf := make([]byte, db.pageSize)
+		encodeFreelist(chunk, next, buf)
+		if err := db.writePage(id, buf); err != nil {
+			return err
+		}
+	}
+	tx.meta.freelist = 0
+	if len(flPages) > 0 {
+		tx.meta.freelist = flPages[0]
+	}
+	if err := db.file.Sync(); err != nil {
+		return fmt.Errorf("btree: sync: %w", err)
+	}
+
+	tx.meta.txid++
+	if err := db.writeMeta(tx.meta, pgid(tx.meta.txid%metaPageCount)); err != nil {
+		return err
+	}
+	if err := db.file.Sync(); err != nil {
+		return fmt.Errorf("btree: sync: %w", err)
+	}
+
+	db.meta = tx.meta
+	db.free = free
+	db.flPages = flPages
+	for id, n := range tx.written {
+		db.pages.Set(id, n)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"container/list"
+	"math"
+)
+
+// arcList identifies which of the four ARC lists an element is on.
+type arcList int
+
+const (
+	arcT1 arcList = iota // resident, seen once recently
+	arcT2                // resident, seen at least twice
+	arcB1                // ghost keys recently evicted from T1
+	arcB2                // ghost keys recently evicted from T2
+)
+
+// arcItem is an element of one of the ARC lists. Ghost items keep only
+// the key; their entry is nil.
+type arcItem[K comparable, V any] struct {
+	key   K
+	entry *entry[K, V]
+	list  arcList
+}
+
+// arc implements the Adaptive Replacement Cache of Megiddo and Modha.
+// It balances recency (T1) against frequency (T2) and moves the target
+// size p of T1 according to hits in the ghost lists B1 and B2.
+type arc[K comparable, V any] struct {
+	capacity int
+	p        int // target size of T1
+	lists    [4]*list.List
+	items    map[K]*list.Element // resident and ghost keys
+}
+
+func newARC[K comparable, V any](capacity int) *arc[K, V] {
+	if capacity <= 0 {
+		capacity = math.MaxInt
+	}
+	c := &arc[K, V]{capacity: capacity, items: make(map[K]*list.Element)}
+	for i := range c.lists {
+		c.lists[i] = list.New()
+	}
+	return c
+}
+
+func (c *arc[K, V]) size(l arcList) int {
+	return c.lists[l].Len()
+}
+
+// move relocates el to the front (most recent end) of list to.
+func (c *arc[K, V]) move(el *list.Element, to arcList) {
+	item := el.Value.(*arcItem[K, V])
+	c.lists[item.list].Remove(el)
+	item.list = to
+	c.items[item.key] = c.lists[to].PushFront(item)
+}
+
+// dropLRU forgets the least recent key of a ghost list.
+func (c *arc[K, V]) dropLRU(l arcList) {
+	item := c.lists[l].Remove(c.lists[l].Back()).(*arcItem[K, V])
+	delete(c.items, item.key)
+}
+
+// replace demotes the least recent resident entry of T1 or T2 to the
+// matching ghost list and returns it as the victim.
+func (c *arc[K, V]) replace(inB2 bool) *entry[K, V] {
+	from, to := arcT2, arcB2
+	if t1 := c.size(arcT1); t1 > 0 && (t1 > c.p || (inB2 && t1 == c.p)) {
+		from, to = arcT1, arcB1
+	}
+	if c.size(from) == 0 {
+		// Entries were deleted by the caller; fall back to the other list.
+		from, to = arcT1+arcT2-from, arcB1+arcB2-to
+	}
+	el := c.lists[from].Back()
+	item := el.Value.(*arcItem[K, V])
+	victim := item.entry
+	item.entry = nil
+	c.move(el, to)
+	return victim
+}
+
+func (c *arc[K, V]) peek(key K) (*entry[K, V], bool) {
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := el.Value.(*arcItem[K, V])
+	if item.entry == nil {
+		return nil, false // Ghost
+	}
+	return item.entry, true
+}
+
+func (c *arc[K, V]) touch(e *entry[K, V]) {
+	c.move(c.items[e.key], arcT2)
+}
+
+func (c *arc[K, V]) insert(e *entry[K, V]) []*entry[K, V] {
+	var victims []*entry[K, V]
+	if el, ok := c.items[e.key]; ok {
+		// Ghost hit: adapt p, make room and promote straight to T2.
+		item := el.Value.(*arcItem[K, V])
+		b1, b2 := c.size(arcB1), c.size(arcB2)
+		if item.list == arcB1 {
+			c.p = min(c.capacity, c.p+max(b2/b1, 1))
+		} else {
+			c.p = max(0, c.p-max(b1/b2, 1))
+		}
+		if c.size(arcT1)+c.size(arcT2) >= c.capacity {
+			victims = append(victims, c.replace(item.list == arcB2))
+		}
+		item.entry = e
+		c.move(el, arcT2)
+		return victims
+	}
+
+	t1, b1 := c.size(arcT1), c.size(arcB1)
+	total := t1 + b1 + c.size(arcT2) + c.size(arcB2)
+	switch {
+	case t1+b1 >= c.capacity:
+		if t1 < c.capacity {
+			c.dropLRU(arcB1)
+			if t1+c.size(arcT2) >= c.capacity {
+				victims = append(victims, c.replace(false))
+			}
+		} else {
+			item := c.lists[arcT1].Remove(c.lists[arcT1].Back()).(*arcItem[K, V])
+			delete(c.items, item.key)
+			victims = append(victims, item.entry)
+		}
+	case total >= c.capacity:
+		if total >= 2*c.capacity {
+			c.dropLRU(arcB2)
+		}
+		if t1+c.size(arcT2) >= c.capacity {
+			victims = append(victims, c.replace(false))
+		}
+	}
+	c.items[e.key] = c.lists[arcT1].PushFront(&arcItem[K, V]{key: e.key, entry: e, list: arcT1})
+	return victims
+}
+
+func (c *arc[K, V]) remove(key K) (*entry[K, V], bool) {
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := el.Value.(*arcItem[K, V])
+	if item.entry == nil {
+		return nil, false // Ghost
+	}
+	c.lists[item.list].Remove(el)
+	delete(c.items, key)
+	return item.entry, true
+}
+
+func (c *arc[K, V]) entries() []*entry[K, V] {
+	entries := make([]*entry[K, V], 0, c.len())
+	for _, l := range []arcList{arcT1, arcT2} {
+		for el := c.lists[l].Front(); el != nil; el = el.Next() {
+			entries = append(entries, el.Value.(*arcItem[K, V]).entry)
+		}
+	}
+	return entries
+}
+
+func (c *arc[K, V]) len() int {
+	return c.size(arcT1) + c.size(arcT2)
+}
+
+func (c *arc[K, V]) clear() {
+	for _, l := range c.lists {
+		l.Init()
+	}
+	c.items = make(map[K]*list.Element)
+	c.p = 0
+}
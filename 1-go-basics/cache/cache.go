@@ -0,0 +1,341 @@
+// Package cache provides bounded in-memory caches with LRU, LFU and ARC
+// eviction policies, per-entry expiry, eviction callbacks, hit/miss
+// statistics, an optional goroutine-safe mode and a generic memoizer.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Policy selects the eviction policy of a cache.
+type Policy int
+
+const (
+	LRU Policy = iota // Least Recently Used
+	LFU               // Least Frequently Used, ties broken by recency
+	ARC               // Adaptive Replacement Cache
+)
+
+// String returns the name of the policy.
+func (p Policy) String() string {
+	switch p {
+	case LRU:
+		return "LRU"
+	case LFU:
+		return "LFU"
+	case ARC:
+		return "ARC"
+	}
+	return "Unknown"
+}
+
+// EvictReason tells an eviction callback why an entry left the cache.
+type EvictReason int
+
+const (
+	Evicted  EvictReason = iota // removed by the policy to make room
+	Expired                     // its TTL elapsed
+	Removed                     // deleted by Delete or Clear
+	Replaced                    // overwritten by Set with the same key
+)
+
+// String returns the name of the reason.
+func (r EvictReason) String() string {
+	switch r {
+	case Evicted:
+		return "evicted"
+	case Expired:
+		return "expired"
+	case Removed:
+		return "removed"
+	case Replaced:
+		return "replaced"
+	}
+	return "unknown"
+}
+
+// Options configures a cache created with New.
+type Options[K comparable, V any] struct {
+	// Capacity is the maximum number of entries. Zero or less means unbounded.
+	Capacity int
+	// TTL is the default lifetime of entries stored with Set.
+	// Zero means entries do not expire.
+	TTL time.Duration
+	// OnEvict, if set, is called whenever an entry leaves the cache.
+	// In a synchronized cache it runs with the cache lock held, so it
+	// must not call back into the cache.
+	OnEvict func(key K, value V, reason EvictReason)
+	// Synchronized makes the cache safe for use by multiple goroutines.
+	Synchronized bool
+	// Now returns the current time. Defaults to time.Now; tests can
+	// replace it to control expiry.
+	Now func() time.Time
+}
+
+// Stats holds cache counters.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64 // entries removed by the policy to make room
+	Expired   uint64 // entries dropped because their TTL elapsed
+}
+
+// HitRate returns the fraction of lookups that were hits, or 0 if there
+// were no lookups.
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Cache is a key-value cache with a bounded number of entries.
+type Cache[K comparable, V any] interface {
+	// Get returns the value stored under key and records an access.
+	// Returns false if the key is absent or has expired.
+	Get(key K) (V, bool)
+	// Set stores value under key with the default TTL.
+	Set(key K, value V)
+	// SetWithTTL stores value under key for the given duration.
+	// A zero ttl means the entry does not expire.
+	SetWithTTL(key K, value V, ttl time.Duration)
+	// Delete removes key. Returns true if it was present.
+	Delete(key K) bool
+	// Len returns the number of entries, including expired ones that
+	// have not been purged yet.
+	Len() int
+	// PurgeExpired drops every expired entry and returns how many were dropped.
+	PurgeExpired() int
+	// Clear removes every entry.
+	Clear()
+	// Stats returns a snapshot of the cache counters.
+	Stats() Stats
+}
+
+// entry is a cached value. A zero expires means it never expires.
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// engine is an eviction policy. It owns the entries and decides which
+// ones to evict; expiry, statistics and callbacks live in cache.
+type engine[K comparable, V any] interface {
+	// peek returns the entry for key without recording an access.
+	peek(key K) (*entry[K, V], bool)
+	// touch records an access to an entry returned by peek.
+	touch(e *entry[K, V])
+	// insert adds a new entry and returns the entries evicted to make room.
+	insert(e *entry[K, V]) []*entry[K, V]
+	// remove deletes the entry for key.
+	remove(key K) (*entry[K, V], bool)
+	// entries returns every live entry.
+	entries() []*entry[K, V]
+	len() int
+	clear()
+}
+
+// cache implements Cache on top of an engine.
+type cache[K comparable, V any] struct {
+	engine  engine[K, V]
+	ttl     time.Duration
+	onEvict func(K, V, EvictReason)
+	now     func() time.Time
+	stats   Stats
+}
+
+// New returns a cache using the given eviction policy.
+func New[K comparable, V any](policy Policy, opts Options[K, V]) Cache[K, V] {
+	var eng engine[K, V]
+	switch policy {
+	case LFU:
+		eng = newLFU[K, V](opts.Capacity)
+	case ARC:
+		eng = newARC[K, V](opts.Capacity)
+	default:
+		eng = newLRU[K, V](opts.Capacity)
+	}
+	c := &cache[K, V]{engine: eng, ttl: opts.TTL, onEvict: opts.OnEvict, now: opts.Now}
+	if c.now == nil {
+		c.now = time.Now
+	}
+	if opts.Synchronized {
+		return &syncCache[K, V]{inner: c}
+	}
+	return c
+}
+
+// NewLRU returns an unsynchronized LRU cache holding at most capacity entries.
+func NewLRU[K comparable, V any](capacity int) Cache[K, V] {
+	return New(LRU, Options[K, V]{Capacity: capacity})
+}
+
+// NewLFU returns an unsynchronized LFU cache holding at most capacity entries.
+func NewLFU[K comparable, V any](capacity int) Cache[K, V] {
+	return New(LFU, Options[K, V]{Capacity: capacity})
+}
+
+// NewARC returns an unsynchronized ARC cache holding at most capacity entries.
+func NewARC[K comparable, V any](capacity int) Cache[K, V] {
+	return New(ARC, Options[K, V]{Capacity: capacity})
+}
+
+func (c *cache[K, V]) expired(e *entry[K, V]) bool {
+	return !e.expires.IsZero() && !c.now().Before(e.expires)
+}
+
+func (c *cache[K, V]) notify(e *entry[K, V], reason EvictReason) {
+	if c.onEvict != nil {
+		c.onEvict(e.key, e.value, reason)
+	}
+}
+
+func (c *cache[K, V]) Get(key K) (V, bool) {
+	e, ok := c.engine.peek(key)
+	if ok && c.expired(e) {
+		c.engine.remove(key)
+		c.stats.Expired++
+		c.notify(e, Expired)
+		ok = false
+	}
+	if !ok {
+		c.stats.Misses++
+		var zero V
+		return zero, false
+	}
+	c.engine.touch(e)
+	c.stats.Hits++
+	return e.value, true
+}
+
+func (c *cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+func (c *cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = c.now().Add(ttl)
+	}
+	if e, ok := c.engine.peek(key); ok {
+		old := *e
+		e.value, e.expires = value, expires
+		c.engine.touch(e)
+		c.notify(&old, Replaced)
+		return
+	}
+	for _, victim := range c.engine.insert(&entry[K, V]{key: key, value: value, expires: expires}) {
+		c.stats.Evictions++
+		c.notify(victim, Evicted)
+	}
+}
+
+func (c *cache[K, V]) Delete(key K) bool {
+	e, ok := c.engine.remove(key)
+	if ok {
+		c.notify(e, Removed)
+	}
+	return ok
+}
+
+func (c *cache[K, V]) Len() int {
+	return c.engine.len()
+}
+
+func (c *cache[K, V]) PurgeExpired() int {
+	purged := 0
+	for _, e := range c.engine.entries() {
+		if c.expired(e) {
+			c.engine.remove(e.key)
+			c.stats.Expired++
+			c.notify(e, Expired)
+			purged++
+		}
+	}
+	return purged
+}
+
+func (c *cache[K, V]) Clear() {
+	entries := c.engine.entries()
+	c.engine.clear()
+	for _, e := range entries {
+		c.notify(e, Removed)
+	}
+}
+
+func (c *cache[K, V]) Stats() Stats {
+	return c.stats
+}
+
+// syncCache guards a cache with a mutex. Every method takes the lock,
+// including Get, because a lookup updates recency and statistics.
+type syncCache[K comparable, V any] struct {
+	mu    sync.Mutex
+	inner *cache[K, V]
+}
+
+func (s *syncCache[K, V]) Get(key K) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Get(key)
+}
+
+func (s *syncCache[K, V]) Set(key K, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inner.Set(key, value)
+}
+
+func (s *syncCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inner.SetWithTTL(key, value, ttl)
+}
+
+func (s *syncCache[K, V]) Delete(key K) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Delete(key)
+}
+
+func (s *syncCache[K, V]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Len()
+}
+
+func (s *syncCache[K, V]) PurgeExpired() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.PurgeExpired()
+}
+
+func (s *syncCache[K, V]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inner.Clear()
+}
+
+func (s *syncCache[K, V]) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Stats()
+}
+
+// Memoize wraps fn so results are stored in c and reused on later calls
+// with the same argument. With a synchronized cache the returned function
+// is safe for concurrent use, although two goroutines missing on the same
+// key at once may both call fn.
+func Memoize[K comparable, V any](fn func(K) V, c Cache[K, V]) func(K) V {
+	return func(key K) V {
+		if value, found := c.Get(key); found {
+			return value
+		}
+		value := fn(key)
+		c.Set(key, value)
+		return value
+	}
+}
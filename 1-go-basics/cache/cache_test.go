@@ -0,0 +1,451 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// run applies steps to c and returns the keys evicted by the policy, in
+// order. A step "+k" sets k; a bare "k" gets it.
+func run(t *testing.T, policy Policy, capacity int, steps []string) (Cache[string, int], []string) {
+	t.Helper()
+	var evicted []string
+	c := New(policy, Options[string, int]{
+		Capacity: capacity,
+		OnEvict: func(key string, _ int, reason EvictReason) {
+			if reason == Evicted {
+				evicted = append(evicted, key)
+			}
+		},
+	})
+	for i, step := range steps {
+		if key, ok := strings.CutPrefix(step, "+"); ok {
+			c.Set(key, i)
+		} else {
+			c.Get(step)
+		}
+	}
+	return c, evicted
+}
+
+// residents returns the sorted keys held by c.
+func residents(c Cache[string, int]) []string {
+	var keys []string
+	for _, e := range c.(*cache[string, int]).engine.entries() {
+		keys = append(keys, e.key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		capacity int
+		steps    []string
+		evicted  []string
+		resident []string
+	}{
+		// LRU evicts whatever was used least recently.
+		{"LRU/recency", LRU, 2, []string{"+a", "+b", "a", "+c"}, []string{"b"}, []string{"a", "c"}},
+		{"LRU/hot key", LRU, 2, []string{"+a", "a", "a", "+b", "b", "+c"}, []string{"a"}, []string{"b", "c"}},
+		{"LRU/miss", LRU, 2, []string{"+a", "+b", "z", "+c"}, []string{"a"}, []string{"b", "c"}},
+		{"LRU/overwrite", LRU, 2, []string{"+a", "+b", "+a", "+c"}, []string{"b"}, []string{"a", "c"}},
+		{"LRU/scan", LRU, 2, []string{"+a", "a", "+x", "+y", "+z"}, []string{"a", "x"}, []string{"y", "z"}},
+		{"LRU/unbounded", LRU, 0, []string{"+a", "+b", "+c", "+d"}, nil, []string{"a", "b", "c", "d"}},
+		// LFU evicts the least used key, the least recent among ties.
+		{"LFU/hot key", LFU, 2, []string{"+a", "a", "a", "+b", "b", "+c"}, []string{"b"}, []string{"a", "c"}},
+		{"LFU/tie", LFU, 2, []string{"+a", "+b", "a", "b", "+c"}, []string{"a"}, []string{"b", "c"}},
+		{"LFU/new key", LFU, 2, []string{"+a", "a", "+b", "b", "+c", "+d"}, []string{"a", "c"}, []string{"b", "d"}},
+		{"LFU/scan", LFU, 2, []string{"+a", "a", "+x", "+y", "+z"}, []string{"x", "y"}, []string{"a", "z"}},
+		{"LFU/unbounded", LFU, -1, []string{"+a", "+b", "+c"}, nil, []string{"a", "b", "c"}},
+		// ARC protects keys seen twice from a scan of keys seen once.
+		{"ARC/recency", ARC, 2, []string{"+a", "+b", "+c"}, []string{"a"}, []string{"b", "c"}},
+		{"ARC/frequent", ARC, 2, []string{"+a", "+b", "a", "b", "+c"}, []string{"a"}, []string{"b", "c"}},
+		{"ARC/scan", ARC, 2, []string{"+a", "a", "+x", "+y", "+z"}, []string{"x", "y"}, []string{"a", "z"}},
+		// A ghost hit on y grows T1's target and brings y back into T2.
+		{"ARC/ghost", ARC, 2, []string{"+a", "a", "+x", "+y", "+z", "+y"}, []string{"x", "y", "a"}, []string{"y", "z"}},
+		{"ARC/unbounded", ARC, 0, []string{"+a", "+b", "+c"}, nil, []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, evicted := run(t, tt.policy, tt.capacity, tt.steps)
+			if !slices.Equal(evicted, tt.evicted) {
+				t.Errorf("evicted %v, want %v", evicted, tt.evicted)
+			}
+			if got := residents(c); !slices.Equal(got, tt.resident) {
+				t.Errorf("resident keys %v, want %v", got, tt.resident)
+			}
+			if c.Len() != len(tt.resident) {
+				t.Errorf("Len = %d, want %d", c.Len(), len(tt.resident))
+			}
+			if got := c.Stats().Evictions; got != uint64(len(tt.evicted)) {
+				t.Errorf("Stats().Evictions = %d, want %d", got, len(tt.evicted))
+			}
+		})
+	}
+}
+
+func TestDeleteAndReuse(t *testing.T) {
+	for _, policy := range []Policy{LRU, LFU, ARC} {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := New(policy, Options[string, int]{Capacity: 3})
+			for i, k := range []string{"a", "b", "c"} {
+				c.Set(k, i)
+				c.Get(k)
+			}
+			if !c.Delete("b") || c.Delete("b") || c.Delete("z") {
+				t.Fatal("Delete should report true once for a present key only")
+			}
+			c.Set("d", 3)
+			c.Set("e", 4)
+			if c.Len() != 3 {
+				t.Fatalf("Len = %d, want 3", c.Len())
+			}
+			if v, ok := c.Get("e"); !ok || v != 4 {
+				t.Errorf("Get(e) = %d, %v, want 4, true", v, ok)
+			}
+			c.Clear()
+			if c.Len() != 0 {
+				t.Fatalf("Len after Clear = %d", c.Len())
+			}
+			if _, ok := c.Get("a"); ok {
+				t.Error("Get after Clear found a key")
+			}
+			c.Set("f", 5)
+			if v, ok := c.Get("f"); !ok || v != 5 {
+				t.Errorf("Get(f) after Clear = %d, %v", v, ok)
+			}
+		})
+	}
+}
+
+func TestTTL(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	type event struct {
+		key    string
+		reason EvictReason
+	}
+	for _, policy := range []Policy{LRU, LFU, ARC} {
+		t.Run(policy.String(), func(t *testing.T) {
+			clock := now
+			var events []event
+			c := New(policy, Options[string, int]{
+				Capacity: 10,
+				TTL:      time.Minute,
+				Now:      func() time.Time { return clock },
+				OnEvict:  func(k string, _ int, r EvictReason) { events = append(events, event{k, r}) },
+			})
+			c.Set("default", 1)
+			c.SetWithTTL("short", 2, 10*time.Second)
+			c.SetWithTTL("forever", 3, 0)
+
+			clock = clock.Add(10*time.Second - 1)
+			if _, ok := c.Get("short"); !ok {
+				t.Fatal("short expired before its TTL")
+			}
+			clock = clock.Add(1)
+			if _, ok := c.Get("short"); ok {
+				t.Fatal("short still present at its deadline")
+			}
+			if c.Len() != 2 {
+				t.Errorf("Len = %d after Get dropped an expired entry, want 2", c.Len())
+			}
+
+			// Setting again restarts the default TTL.
+			clock = clock.Add(40 * time.Second)
+			c.Set("default", 4)
+			clock = clock.Add(30 * time.Second)
+			if v, ok := c.Get("default"); !ok || v != 4 {
+				t.Fatalf("Get(default) = %d, %v after it was refreshed", v, ok)
+			}
+			clock = clock.Add(30 * time.Second)
+			if n := c.PurgeExpired(); n != 1 {
+				t.Errorf("PurgeExpired = %d, want 1", n)
+			}
+			if n := c.PurgeExpired(); n != 0 {
+				t.Errorf("second PurgeExpired = %d, want 0", n)
+			}
+			clock = clock.Add(24 * time.Hour)
+			if v, ok := c.Get("forever"); !ok || v != 3 {
+				t.Errorf("Get(forever) = %d, %v", v, ok)
+			}
+
+			want := []event{{"short", Expired}, {"default", Replaced}, {"default", Expired}}
+			if !slices.Equal(events, want) {
+				t.Errorf("callbacks %v, want %v", events, want)
+			}
+			if s := c.Stats(); s.Expired != 2 || s.Evictions != 0 {
+				t.Errorf("Stats = %+v, want 2 expired and no evictions", s)
+			}
+		})
+	}
+}
+
+func TestOnEvict(t *testing.T) {
+	type event struct {
+		key    string
+		value  int
+		reason EvictReason
+	}
+	var events []event
+	c := New(LRU, Options[string, int]{
+		Capacity: 2,
+		OnEvict:  func(k string, v int, r EvictReason) { events = append(events, event{k, v, r}) },
+	})
+	c.Set("a", 1)
+	c.Set("a", 2)
+	c.Set("b", 3)
+	c.Set("c", 4)
+	c.Delete("b")
+	c.Delete("b")
+	c.Clear()
+	want := []event{
+		{"a", 1, Replaced},
+		{"a", 2, Evicted},
+		{"b", 3, Removed},
+		{"c", 4, Removed},
+	}
+	if !slices.Equal(events, want) {
+		t.Errorf("callbacks %v, want %v", events, want)
+	}
+}
+
+func TestStats(t *testing.T) {
+	c := NewLFU[int, int](2)
+	if rate := c.Stats().HitRate(); rate != 0 {
+		t.Errorf("HitRate with no lookups = %v, want 0", rate)
+	}
+	c.Set(1, 1)
+	c.Set(2, 2)
+	c.Get(1)
+	c.Get(1)
+	c.Get(3)
+	c.Set(3, 3)
+	c.Get(2)
+	want := Stats{Hits: 2, Misses: 2, Evictions: 1}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+	if rate := c.Stats().HitRate(); rate != 0.5 {
+		t.Errorf("HitRate = %v, want 0.5", rate)
+	}
+	c.Clear()
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats after Clear = %+v, want them kept", got)
+	}
+}
+
+func TestMemoize(t *testing.T) {
+	calls := map[int]int{}
+	square := Memoize(func(n int) int {
+		calls[n]++
+		return n * n
+	}, NewLRU[int, int](2))
+	for _, n := range []int{3, 3, 4, 3, 5, 4, 3} {
+		if got := square(n); got != n*n {
+			t.Fatalf("square(%d) = %d", n, got)
+		}
+	}
+	// 4 was evicted by 5, then 3 by 4.
+	want := map[int]int{3: 2, 4: 2, 5: 1}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("calls %v, want %v", calls, want)
+	}
+}
+
+func TestSynchronized(t *testing.T) {
+	for _, policy := range []Policy{LRU, LFU, ARC} {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := New(policy, Options[int, int]{Capacity: 64, Synchronized: true})
+			double := Memoize(func(n int) int { return 2 * n }, c)
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < 500; i++ {
+						n := (i*7 + g) % 100
+						if got := double(n); got != 2*n {
+							t.Errorf("double(%d) = %d", n, got)
+							return
+						}
+						if i%50 == 0 {
+							c.Delete(n)
+							c.PurgeExpired()
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			if c.Len() > 64 {
+				t.Errorf("Len = %d, over capacity", c.Len())
+			}
+			if s := c.Stats(); s.Hits+s.Misses != 8*500 {
+				t.Errorf("Stats = %+v, want %d lookups", s, 8*500)
+			}
+		})
+	}
+}
+
+// checkEngine checks the internal invariants of an eviction policy.
+func checkEngine[K comparable, V any](eng engine[K, V]) error {
+	switch c := eng.(type) {
+	case *lru[K, V]:
+		if len(c.items) != c.order.Len() || len(c.items) > c.capacity {
+			return fmt.Errorf("lru has %d keys and %d list elements for capacity %d", len(c.items), c.order.Len(), c.capacity)
+		}
+		for el := c.order.Front(); el != nil; el = el.Next() {
+			if e := el.Value.(*entry[K, V]); c.items[e.key] != el {
+				return fmt.Errorf("lru key %v is not indexed", e.key)
+			}
+		}
+	case *lfu[K, V]:
+		n, lowest := 0, 0
+		for freq, b := range c.buckets {
+			if b.Len() == 0 {
+				return fmt.Errorf("lfu keeps an empty bucket for frequency %d", freq)
+			}
+			if lowest == 0 || freq < lowest {
+				lowest = freq
+			}
+			for el := b.Front(); el != nil; el = el.Next() {
+				item := el.Value.(*lfuItem[K, V])
+				if item.freq != freq || c.items[item.entry.key] != el {
+					return fmt.Errorf("lfu key %v with frequency %d is in bucket %d", item.entry.key, item.freq, freq)
+				}
+				n++
+			}
+		}
+		if n != len(c.items) || n > c.capacity {
+			return fmt.Errorf("lfu has %d keys and %d bucketed items for capacity %d", len(c.items), n, c.capacity)
+		}
+		if n > 0 && c.minFreq != lowest {
+			return fmt.Errorf("lfu minFreq is %d, want %d", c.minFreq, lowest)
+		}
+	case *arc[K, V]:
+		n := 0
+		for l, lst := range c.lists {
+			for el := lst.Front(); el != nil; el = el.Next() {
+				item := el.Value.(*arcItem[K, V])
+				ghost := arcList(l) >= arcB1
+				if item.list != arcList(l) || c.items[item.key] != el || ghost != (item.entry == nil) {
+					return fmt.Errorf("arc key %v is misfiled on list %d", item.key, l)
+				}
+				n++
+			}
+		}
+		t1, t2, b1, b2 := c.size(arcT1), c.size(arcT2), c.size(arcB1), c.size(arcB2)
+		switch {
+		case n != len(c.items):
+			return fmt.Errorf("arc has %d keys and %d list elements", len(c.items), n)
+		case t1+t2 > c.capacity || t1+b1 > c.capacity || t1+t2+b1+b2 > 2*c.capacity:
+			return fmt.Errorf("arc lists T1=%d T2=%d B1=%d B2=%d exceed capacity %d", t1, t2, b1, b2, c.capacity)
+		case c.p < 0 || c.p > c.capacity:
+			return fmt.Errorf("arc target %d out of range [0:%d]", c.p, c.capacity)
+		}
+	default:
+		return fmt.Errorf("unknown engine %T", eng)
+	}
+	return nil
+}
+
+var errModelMismatch = errors.New("cache: result differs from the reference model")
+
+// checkOps replays data, two bytes per operation, against a small cache
+// with an injected clock. After every operation it checks the engine and
+// compares the resident keys with a model kept from the callbacks.
+func checkOps(policy Policy, data []byte) error {
+	type modelEntry struct {
+		value   byte
+		expires time.Time
+	}
+	clock := time.Unix(0, 0)
+	model := make(map[byte]modelEntry)
+	c := New(policy, Options[byte, byte]{
+		Capacity: 4,
+		TTL:      3 * time.Second,
+		Now:      func() time.Time { return clock },
+		OnEvict: func(key, _ byte, reason EvictReason) {
+			if reason != Replaced {
+				delete(model, key)
+			}
+		},
+	})
+	expired := func(e modelEntry) bool { return !e.expires.IsZero() && !clock.Before(e.expires) }
+	for i := 0; i+1 < len(data); i += 2 {
+		op, arg := data[i], data[i+1]
+		fail := func(err error) error { return fmt.Errorf("%v, operation %d: %w", policy, i/2+1, err) }
+		key := arg % 8
+		switch op % 5 {
+		case 0:
+			c.Set(key, op)
+			model[key] = modelEntry{op, clock.Add(3 * time.Second)}
+		case 1:
+			ttl := time.Duration(arg%3) * time.Second
+			c.SetWithTTL(key, op, ttl)
+			e := modelEntry{value: op}
+			if ttl > 0 {
+				e.expires = clock.Add(ttl)
+			}
+			model[key] = e
+		case 2:
+			want, ok := model[key]
+			ok = ok && !expired(want)
+			if value, found := c.Get(key); found != ok || (ok && value != want.value) {
+				return fail(errModelMismatch)
+			}
+		case 3:
+			_, ok := model[key]
+			if c.Delete(key) != ok {
+				return fail(errModelMismatch)
+			}
+		case 4:
+			clock = clock.Add(time.Duration(arg%4) * time.Second)
+			if arg%2 == 0 {
+				c.PurgeExpired()
+			}
+		}
+		inner := c.(*cache[byte, byte])
+		if err := checkEngine(inner.engine); err != nil {
+			return fail(err)
+		}
+		if c.Len() != len(model) {
+			return fail(errModelMismatch)
+		}
+		for _, e := range inner.engine.entries() {
+			if _, ok := model[e.key]; !ok {
+				return fail(errModelMismatch)
+			}
+		}
+	}
+	return nil
+}
+
+// FuzzPolicies replays each input against every policy. Run it with
+//
+//	go test -fuzz FuzzPolicies ./cache
+func FuzzPolicies(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 1, 0, 2, 0, 3, 2, 1, 0, 4, 0, 5, 2, 2, 3, 3, 4, 3, 2, 1})
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 20; i++ {
+		data := make([]byte, rng.Intn(400))
+		rng.Read(data)
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, policy := range []Policy{LRU, LFU, ARC} {
+			if err := checkOps(policy, data); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"container/list"
+	"math"
+)
+
+// lfuItem is an entry together with its access count.
+type lfuItem[K comparable, V any] struct {
+	entry *entry[K, V]
+	freq  int
+}
+
+// lfu evicts the least frequently used entry, and among those the least
+// recently used. Entries are bucketed by frequency, each bucket being a
+// recency-ordered list, so every operation is O(1).
+type lfu[K comparable, V any] struct {
+	capacity int
+	items    map[K]*list.Element // element values are *lfuItem
+	buckets  map[int]*list.List  // frequency -> items, most recent at front
+	minFreq  int
+}
+
+func newLFU[K comparable, V any](capacity int) *lfu[K, V] {
+	if capacity <= 0 {
+		capacity = math.MaxInt
+	}
+	return &lfu[K, V]{
+		capacity: capacity,
+		items:    make(map[K]*list.Element),
+		buckets:  make(map[int]*list.List),
+	}
+}
+
+func (c *lfu[K, V]) bucket(freq int) *list.List {
+	b, ok := c.buckets[freq]
+	if !ok {
+		b = list.New()
+		c.buckets[freq] = b
+	}
+	return b
+}
+
+// unlink removes el from its bucket, dropping the bucket if it empties.
+func (c *lfu[K, V]) unlink(el *list.Element) *lfuItem[K, V] {
+	item := el.Value.(*lfuItem[K, V])
+	b := c.buckets[item.freq]
+	b.Remove(el)
+	if b.Len() == 0 {
+		delete(c.buckets, item.freq)
+	}
+	return item
+}
+
+func (c *lfu[K, V]) peek(key K) (*entry[K, V], bool) {
+	if el, ok := c.items[key]; ok {
+		return el.Value.(*lfuItem[K, V]).entry, true
+	}
+	return nil, false
+}
+
+func (c *lfu[K, V]) touch(e *entry[K, V]) {
+	item := c.unlink(c.items[e.key])
+	if item.freq == c.minFreq && c.buckets[item.freq] == nil {
+		c.minFreq++
+	}
+	item.freq++
+	c.items[e.key] = c.bucket(item.freq).PushFront(item)
+}
+
+func (c *lfu[K, V]) insert(e *entry[K, V]) []*entry[K, V] {
+	var victims []*entry[K, V]
+	for len(c.items) >= c.capacity {
+		item := c.unlink(c.buckets[c.minFreq].Back())
+		delete(c.items, item.entry.key)
+		victims = append(victims, item.entry)
+		if len(c.items) > 0 && c.buckets[c.minFreq] == nil {
+			c.recomputeMinFreq()
+		}
+	}
+	c.items[e.key] = c.bucket(1).PushFront(&lfuItem[K, V]{entry: e, freq: 1})
+	c.minFreq = 1
+	return victims
+}
+
+func (c *lfu[K, V]) remove(key K) (*entry[K, V], bool) {
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	delete(c.items, key)
+	item := c.unlink(el)
+	if item.freq == c.minFreq && c.buckets[item.freq] == nil {
+		c.recomputeMinFreq()
+	}
+	return item.entry, true
+}
+
+// recomputeMinFreq scans the buckets after the lowest one was emptied by
+// a removal. Only Delete and expiry take this path, never touch.
+func (c *lfu[K, V]) recomputeMinFreq() {
+	c.minFreq = 0
+	for freq := range c.buckets {
+		if c.minFreq == 0 || freq < c.minFreq {
+			c.minFreq = freq
+		}
+	}
+}
+
+func (c *lfu[K, V]) entries() []*entry[K, V] {
+	entries := make([]*entry[K, V], 0, len(c.items))
+	for _, el := range c.items {
+		entries = append(entries, el.Value.(*lfuItem[K, V]).entry)
+	}
+	return entries
+}
+
+func (c *lfu[K, V]) len() int {
+	return len(c.items)
+}
+
+func (c *lfu[K, V]) clear() {
+	c.items = make(map[K]*list.Element)
+	c.buckets = make(map[int]*list.List)
+	c.minFreq = 0
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"container/list"
+	"math"
+)
+
+// lru evicts the least recently used entry. The list is ordered from most
+// recently used (front) to least recently used (back).
+type lru[K comparable, V any] struct {
+	capacity int
+	order    *list.List
+	items    map[K]*list.Element
+}
+
+func newLRU[K comparable, V any](capacity int) *lru[K, V] {
+	if capacity <= 0 {
+		capacity = math.MaxInt
+	}
+	return &lru[K, V]{capacity: capacity, order: list.New(), items: make(map[K]*list.Element)}
+}
+
+func (c *lru[K, V]) peek(key K) (*entry[K, V], bool) {
+	if el, ok := c.items[key]; ok {
+		return el.Value.(*entry[K, V]), true
+	}
+	return nil, false
+}
+
+func (c *lru[K, V]) touch(e *entry[K, V]) {
+	c.order.MoveToFront(c.items[e.key])
+}
+
+func (c *lru[K, V]) insert(e *entry[K, V]) []*entry[K, V] {
+	var victims []*entry[K, V]
+	for c.order.Len() >= c.capacity {
+		oldest := c.order.Remove(c.order.Back()).(*entry[K, V])
+		delete(c.items, oldest.key)
+		victims = append(victims, oldest)
+	}
+	c.items[e.key] = c.order.PushFront(e)
+	return victims
+}
+
+func (c *lru[K, V]) remove(key K) (*entry[K, V], bool) {
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	delete(c.items, key)
+	return c.order.Remove(el).(*entry[K, V]), true
+}
+
+func (c *lru[K, V]) entries() []*entry[K, V] {
+	entries := make([]*entry[K, V], 0, c.order.Len())
+	for el := c.order.Front(); el != nil; el = el.Next() {
+		entries = append(entries, el.Value.(*entry[K, V]))
+	}
+	return entries
+}
+
+func (c *lru[K, V]) len() int {
+	return c.order.Len()
+}
+
+func (c *lru[K, V]) clear() {
+	c.order.Init()
+	c.items = make(map[K]*list.Element)
+}
@@ -3,12 +3,13 @@
 package datastructures
 
 import (
+	"cmp"
 	"container/heap"
 	"container/list"
-	"container/ring"
 	"fmt"
 	"math/rand"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -17,63 +18,275 @@ import (
 // ======================================================
 
 // ListNode represents a node in a singly linked list.
-type ListNode struct {
-	Data interface{}
-	Next *ListNode
+type ListNode[T any] struct {
+	Data T
+	Next *ListNode[T]
 }
 
-// LinkedList represents a singly linked list.
-type LinkedList struct {
-	Head *ListNode
+// LinkedList represents a singly linked list of values of type T.
+// Head and the Next links are exported for traversal; changing them
+// directly bypasses the cached tail and length, so modify the list
+// through its methods. The zero value is an empty list.
+type LinkedList[T any] struct {
+	Head   *ListNode[T]
+	tail   *ListNode[T]
+	length int
 }
 
-// Append adds a new node with the given data at the end of the list.
-func (l *LinkedList) Append(data interface{}) {
-	newNode := &ListNode{Data: data}
+// Len returns the number of nodes in the list.
+func (l *LinkedList[T]) Len() int {
+	return l.length
+}
+
+// Append adds a new node with the given data at the end of the list in O(1).
+func (l *LinkedList[T]) Append(data T) {
+	newNode := &ListNode[T]{Data: data}
 	if l.Head == nil {
 		l.Head = newNode
-		return
+	} else {
+		l.tail.Next = newNode
 	}
+	l.tail = newNode
+	l.length++
+}
+
+// Prepend adds a new node with the given data at the beginning of the list.
+func (l *LinkedList[T]) Prepend(data T) {
+	newNode := &ListNode[T]{Data: data, Next: l.Head}
+	l.Head = newNode
+	if l.tail == nil {
+		l.tail = newNode
+	}
+	l.length++
+}
+
+// nodeAt returns the node at index i, which must be in range.
+func (l *LinkedList[T]) nodeAt(i int) *ListNode[T] {
 	current := l.Head
-	for current.Next != nil {
+	for ; i > 0; i-- {
 		current = current.Next
 	}
-	current.Next = newNode
+	return current
 }
 
-// Prepend adds a new node with the given data at the beginning of the list.
-func (l *LinkedList) Prepend(data interface{}) {
-	newNode := &ListNode{Data: data, Next: l.Head}
-	l.Head = newNode
+// InsertAt inserts data so that it ends up at index i. It panics unless
+// 0 <= i <= Len().
+func (l *LinkedList[T]) InsertAt(i int, data T) {
+	if i < 0 || i > l.length {
+		panic(fmt.Sprintf("datastructures: list insert index %d out of range [0:%d]", i, l.length+1))
+	}
+	switch i {
+	case 0:
+		l.Prepend(data)
+	case l.length:
+		l.Append(data)
+	default:
+		prev := l.nodeAt(i - 1)
+		prev.Next = &ListNode[T]{Data: data, Next: prev.Next}
+		l.length++
+	}
 }
 
-// Delete removes the first node containing the given data.
-func (l *LinkedList) Delete(data interface{}) {
-	if l.Head == nil {
-		return
+// RemoveAt removes and returns the data at index i. It panics unless
+// 0 <= i < Len().
+func (l *LinkedList[T]) RemoveAt(i int) T {
+	if i < 0 || i >= l.length {
+		panic(fmt.Sprintf("datastructures: list index %d out of range [0:%d]", i, l.length))
 	}
-	if l.Head.Data == data {
-		l.Head = l.Head.Next
-		return
+	if i == 0 {
+		removed := l.Head
+		l.Head = removed.Next
+		if l.Head == nil {
+			l.tail = nil
+		}
+		l.length--
+		return removed.Data
 	}
-	current := l.Head
-	for current.Next != nil {
-		if current.Next.Data == data {
-			current.Next = current.Next.Next
-			return
+	prev := l.nodeAt(i - 1)
+	return l.removeAfter(prev)
+}
+
+// removeAfter unlinks the node following prev and returns its data.
+func (l *LinkedList[T]) removeAfter(prev *ListNode[T]) T {
+	removed := prev.Next
+	prev.Next = removed.Next
+	if removed == l.tail {
+		l.tail = prev
+	}
+	l.length--
+	return removed.Data
+}
+
+// DeleteFunc removes the first node whose data satisfies match.
+// Use it for element types that are not comparable with ==.
+// Returns true if a node was removed.
+func (l *LinkedList[T]) DeleteFunc(match func(T) bool) bool {
+	if i := l.IndexFunc(match); i >= 0 {
+		l.RemoveAt(i)
+		return true
+	}
+	return false
+}
+
+// Delete removes the first node of l containing the given data.
+// Returns true if a node was removed.
+func Delete[T comparable](l *LinkedList[T], data T) bool {
+	return l.DeleteFunc(func(v T) bool { return v == data })
+}
+
+// Find returns the data of the first node that satisfies match.
+// Returns false if there is none.
+func (l *LinkedList[T]) Find(match func(T) bool) (T, bool) {
+	for current := l.Head; current != nil; current = current.Next {
+		if match(current.Data) {
+			return current.Data, true
 		}
-		current = current.Next
 	}
+	var zero T
+	return zero, false
 }
 
-// Print displays the linked list.
-func (l *LinkedList) Print() {
+// IndexFunc returns the index of the first node that satisfies match,
+// or -1 if there is none.
+func (l *LinkedList[T]) IndexFunc(match func(T) bool) int {
+	i := 0
+	for current := l.Head; current != nil; current = current.Next {
+		if match(current.Data) {
+			return i
+		}
+		i++
+	}
+	return -1
+}
+
+// IndexOf returns the index of the first node of l containing data, or -1.
+func IndexOf[T comparable](l *LinkedList[T], data T) int {
+	return l.IndexFunc(func(v T) bool { return v == data })
+}
+
+// Reverse reverses the list in place.
+func (l *LinkedList[T]) Reverse() {
+	var prev *ListNode[T]
 	current := l.Head
+	l.tail = current
 	for current != nil {
-		fmt.Printf("%v -> ", current.Data)
-		current = current.Next
+		next := current.Next
+		current.Next = prev
+		prev, current = current, next
 	}
-	fmt.Println("nil")
+	l.Head = prev
+}
+
+// SplitAt cuts the list after its first i nodes and returns the rest as
+// a new list. It panics unless 0 <= i <= Len().
+func (l *LinkedList[T]) SplitAt(i int) *LinkedList[T] {
+	if i < 0 || i > l.length {
+		panic(fmt.Sprintf("datastructures: list split index %d out of range [0:%d]", i, l.length+1))
+	}
+	rest := &LinkedList[T]{}
+	if i == l.length {
+		return rest
+	}
+	if i == 0 {
+		*rest = *l
+		*l = LinkedList[T]{}
+		return rest
+	}
+	last := l.nodeAt(i - 1)
+	rest.Head, rest.tail, rest.length = last.Next, l.tail, l.length-i
+	last.Next = nil
+	l.tail, l.length = last, i
+	return rest
+}
+
+// MergeSortedFunc merges two lists that are sorted by cmp into one sorted
+// list by relinking their nodes, leaving a and b empty. On ties, nodes
+// from a come first.
+func MergeSortedFunc[T any](a, b *LinkedList[T], cmp func(x, y T) int) *LinkedList[T] {
+	merged := &LinkedList[T]{length: a.length + b.length}
+	var dummy ListNode[T]
+	tail := &dummy
+	x, y := a.Head, b.Head
+	for x != nil && y != nil {
+		if cmp(y.Data, x.Data) < 0 {
+			tail.Next, y = y, y.Next
+		} else {
+			tail.Next, x = x, x.Next
+		}
+		tail = tail.Next
+	}
+	switch {
+	case x != nil:
+		tail.Next, tail = x, a.tail
+	case y != nil:
+		tail.Next, tail = y, b.tail
+	}
+	merged.Head = dummy.Next
+	if merged.Head != nil {
+		merged.tail = tail
+	}
+	*a, *b = LinkedList[T]{}, LinkedList[T]{}
+	return merged
+}
+
+// MergeSorted merges two ascending lists into one, leaving a and b empty.
+func MergeSorted[T cmp.Ordered](a, b *LinkedList[T]) *LinkedList[T] {
+	return MergeSortedFunc(a, b, cmp.Compare[T])
+}
+
+// FindCycle uses Floyd's tortoise-and-hare algorithm to look for a cycle
+// in the Next links, which can only appear if they were changed directly.
+// It returns the first node of the cycle, or nil if the list ends.
+func (l *LinkedList[T]) FindCycle() *ListNode[T] {
+	slow, fast := l.Head, l.Head
+	for fast != nil && fast.Next != nil {
+		slow, fast = slow.Next, fast.Next.Next
+		if slow == fast {
+			// The distance from Head to the cycle start equals the
+			// distance from the meeting point to it, going forward.
+			for slow = l.Head; slow != fast; {
+				slow, fast = slow.Next, fast.Next
+			}
+			return slow
+		}
+	}
+	return nil
+}
+
+// HasCycle reports whether the Next links loop back on themselves.
+func (l *LinkedList[T]) HasCycle() bool {
+	return l.FindCycle() != nil
+}
+
+// String formats the list as "a -> b -> nil".
+func (l *LinkedList[T]) String() string {
+	var sb strings.Builder
+	for current := l.Head; current != nil; current = current.Next {
+		fmt.Fprintf(&sb, "%v -> ", current.Data)
+	}
+	sb.WriteString("nil")
+	return sb.String()
+}
+
+// Validate checks that the Next links end without a cycle and that the
+// cached length and tail match the nodes.
+func (l *LinkedList[T]) Validate() error {
+	if start := l.FindCycle(); start != nil {
+		return fmt.Errorf("%w: linked list cycles back to node %v", ErrInvariant, start.Data)
+	}
+	count := 0
+	var last *ListNode[T]
+	for current := l.Head; current != nil; current = current.Next {
+		count++
+		last = current
+	}
+	if count != l.length {
+		return fmt.Errorf("%w: linked list has %d nodes but length %d", ErrInvariant, count, l.length)
+	}
+	if last != l.tail {
+		return fmt.Errorf("%w: linked list tail is not its last node", ErrInvariant)
+	}
+	return nil
 }
 
 // ======================================================
@@ -81,19 +294,20 @@ func (l *LinkedList) Print() {
 // ======================================================
 
 // Stack represents a stack data structure using a slice.
-type Stack struct {
-	items []interface{}
+type Stack[T any] struct {
+	items []T
 }
 
 // Push adds an item onto the stack.
-func (s *Stack) Push(item interface{}) {
+func (s *Stack[T]) Push(item T) {
 	s.items = append(s.items, item)
 }
 
 // Pop removes and returns the top item from the stack. Returns false if empty.
-func (s *Stack) Pop() (interface{}, bool) {
+func (s *Stack[T]) Pop() (T, bool) {
 	if len(s.items) == 0 {
-		return nil, false
+		var zero T
+		return zero, false
 	}
 	index := len(s.items) - 1
 	item := s.items[index]
@@ -102,53 +316,58 @@ func (s *Stack) Pop() (interface{}, bool) {
 }
 
 // Peek returns the top item without removing it. Returns false if empty.
-func (s *Stack) Peek() (interface{}, bool) {
+func (s *Stack[T]) Peek() (T, bool) {
 	if len(s.items) == 0 {
-		return nil, false
+		var zero T
+		return zero, false
 	}
 	return s.items[len(s.items)-1], true
 }
 
 // IsEmpty returns true if the stack is empty.
-func (s *Stack) IsEmpty() bool {
+func (s *Stack[T]) IsEmpty() bool {
 	return len(s.items) == 0
 }
 
+// Len returns the number of items on the stack.
+func (s *Stack[T]) Len() int {
+	return len(s.items)
+}
+
 // ======================================================
 // Queue Implementation
 // ======================================================
 
-// Queue represents a queue data structure using a slice.
-type Queue struct {
-	items []interface{}
+// Queue represents a FIFO queue backed by a growable circular buffer (see Deque).
+// Dequeued items are cleared from the buffer and the buffer shrinks when
+// mostly empty, so a long-running queue does not hold on to memory.
+type Queue[T any] struct {
+	items Deque[T]
 }
 
 // Enqueue adds an item to the end of the queue.
-func (q *Queue) Enqueue(item interface{}) {
-	q.items = append(q.items, item)
+func (q *Queue[T]) Enqueue(item T) {
+	q.items.PushBack(item)
 }
 
 // Dequeue removes and returns the item at the front of the queue. Returns false if empty.
-func (q *Queue) Dequeue() (interface{}, bool) {
-	if len(q.items) == 0 {
-		return nil, false
-	}
-	item := q.items[0]
-	q.items = q.items[1:]
-	return item, true
+func (q *Queue[T]) Dequeue() (T, bool) {
+	return q.items.PopFront()
 }
 
 // Peek returns the item at the front of the queue without removing it. Returns false if empty.
-func (q *Queue) Peek() (interface{}, bool) {
-	if len(q.items) == 0 {
-		return nil, false
-	}
-	return q.items[0], true
+func (q *Queue[T]) Peek() (T, bool) {
+	return q.items.Front()
 }
 
 // IsEmpty returns true if the queue is empty.
-func (q *Queue) IsEmpty() bool {
-	return len(q.items) == 0
+func (q *Queue[T]) IsEmpty() bool {
+	return q.items.IsEmpty()
+}
+
+// Len returns the number of items in the queue.
+func (q *Queue[T]) Len() int {
+	return q.items.Len()
 }
 
 // ======================================================
@@ -163,6 +382,8 @@ type TreeNode struct {
 }
 
 // BinarySearchTree represents a binary search tree.
+// It does not rebalance, so sorted input degrades it into a linked list;
+// use TreeMap for ordered indexes.
 type BinarySearchTree struct {
 	Root *TreeNode
 }
@@ -202,6 +423,48 @@ func searchNode(node *TreeNode, data int) bool {
 	}
 }
 
+// SearchPath returns the nodes Search visits looking for data, from the
+// root down. Returns true if the last node holds data.
+func (bst *BinarySearchTree) SearchPath(data int) ([]*TreeNode, bool) {
+	var path []*TreeNode
+	for node := bst.Root; node != nil; {
+		path = append(path, node)
+		if node.Data == data {
+			return path, true
+		} else if data < node.Data {
+			node = node.Left
+		} else {
+			node = node.Right
+		}
+	}
+	return path, false
+}
+
+// Validate checks that every value is ordered relative to its ancestors:
+// smaller values to the left, equal or larger ones to the right. It also
+// reports a node reachable twice, as the exported links allow.
+func (bst *BinarySearchTree) Validate() error {
+	seen := make(map[*TreeNode]bool)
+	var check func(node *TreeNode, lo, hi *int) error
+	check = func(node *TreeNode, lo, hi *int) error {
+		if node == nil {
+			return nil
+		}
+		if seen[node] {
+			return fmt.Errorf("%w: bst node %d is reachable twice", ErrInvariant, node.Data)
+		}
+		seen[node] = true
+		if (lo != nil && node.Data < *lo) || (hi != nil && node.Data >= *hi) {
+			return fmt.Errorf("%w: bst node %d is on the wrong side of an ancestor", ErrInvariant, node.Data)
+		}
+		if err := check(node.Left, lo, &node.Data); err != nil {
+			return err
+		}
+		return check(node.Right, &node.Data, hi)
+	}
+	return check(bst.Root, nil, nil)
+}
+
 // InOrderTraversal returns the in-order traversal of the BST as a slice.
 func (bst *BinarySearchTree) InOrderTraversal() []int {
 	var result []int
@@ -269,13 +532,33 @@ func (pq *PriorityQueue) Update(item *PriorityQueueItem, value interface{}, prio
 	heap.Fix(pq, item.Index)
 }
 
+// Validate checks the heap property and that each item's Index matches
+// its position. A stale Index, for example after calling Update with an
+// item that was already popped, makes Update fix the wrong position.
+func (pq PriorityQueue) Validate() error {
+	for i, item := range pq {
+		if item == nil {
+			return fmt.Errorf("%w: priority queue item %d is nil", ErrInvariant, i)
+		}
+		if item.Index != i {
+			return fmt.Errorf("%w: priority queue item %d has Index %d", ErrInvariant, i, item.Index)
+		}
+		if parent := pq[(i-1)/2]; i > 0 && parent.Priority > item.Priority {
+			return fmt.Errorf("%w: priority queue item %d has priority %d, lower than its parent's %d",
+				ErrInvariant, i, item.Priority, parent.Priority)
+		}
+	}
+	return nil
+}
+
 // ======================================================
 // Binary Search Algorithm
 // ======================================================
 
-// BinarySearch performs a binary search on a sorted slice of integers.
+// BinarySearch performs a binary search on a sorted slice.
 // Returns the index of the target or -1 if not found.
-func BinarySearch(arr []int, target int) int {
+// See search.go for bound, range and predicate searches.
+func BinarySearch[T cmp.Ordered](arr []T, target T) int {
 	low, high := 0, len(arr)-1
 	for low <= high {
 		mid := low + (high-low)/2
@@ -330,21 +613,16 @@ func DemoContainerHeap() {
 	fmt.Println("container/heap (priority queue) - Highest Priority Task:", itemPopped.Value)
 }
 
-// DemoContainerRing demonstrates the use of container/ring (circular list).
-func DemoContainerRing() {
-	// Create a ring with 5 elements.
-	r := ring.New(5)
-	// Fill the ring with sequential numbers.
-	for i := 0; i < r.Len(); i++ {
-		r.Value = i + 1
-		r = r.Next()
-	}
-	// Iterate over the ring and print the values.
-	fmt.Print("container/ring (circular list): ")
-	r.Do(func(value interface{}) {
-		fmt.Printf("%v ", value)
-	})
-	fmt.Println()
+// DemoRingBuffer demonstrates RingBuffer keeping the last few log lines,
+// the typed replacement for a container/ring circular list.
+func DemoRingBuffer() {
+	logs := NewRingBuffer[string](3, RingOverwrite)
+	logs.Write([]string{"boot", "connect", "sync", "idle", "sync"})
+	fmt.Println("RingBuffer (last 3 log lines):", logs.Slice(), "full:", logs.IsFull()) // Expected: [sync idle sync] full: true
+
+	samples := NewRingBuffer[int](2, RingReject)
+	n, err := samples.Write([]int{10, 20, 30})
+	fmt.Println("RingBuffer reject mode wrote", n, "items:", err) // Expected: 2 items: datastructures: ring buffer is full
 }
 
 // DemoSort demonstrates sorting a slice using the sort package.
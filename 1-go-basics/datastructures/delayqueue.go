@@ -0,0 +1,302 @@
+package datastructures
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ======================================================
+// Delay Queue (Timer Heap)
+// ======================================================
+
+// Clock is the time source of a DelayQueue. The real clock uses the time
+// package; tests can pass a ManualClock and move time by hand.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// At returns a channel that receives once the clock reaches deadline,
+	// and a function that releases the channel if it is no longer needed.
+	At(deadline time.Time) (<-chan time.Time, func())
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) At(deadline time.Time) (<-chan time.Time, func()) {
+	t := time.NewTimer(time.Until(deadline))
+	return t.C, func() { t.Stop() }
+}
+
+// ManualClock is a Clock that only moves when Advance is called.
+type ManualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []manualWaiter
+}
+
+type manualWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewManualClock returns a ManualClock set to start.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the clock's current time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// At returns a channel that receives once Advance reaches deadline, and
+// a function that removes the pending channel without firing it.
+func (c *ManualClock) At(deadline time.Time) (<-chan time.Time, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if !deadline.After(c.now) {
+		ch <- c.now
+	} else {
+		c.waiters = append(c.waiters, manualWaiter{deadline: deadline, ch: ch})
+	}
+	return ch, func() { c.stop(ch) }
+}
+
+// stop removes the waiter for ch, if it has not fired yet.
+func (c *ManualClock) stop(ch chan time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waiters = slices.DeleteFunc(c.waiters, func(w manualWaiter) bool { return w.ch == ch })
+}
+
+// Advance moves the clock forward by d and fires every channel from At
+// whose deadline has been reached.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			pending = append(pending, w)
+		} else {
+			w.ch <- c.now
+		}
+	}
+	clear(c.waiters[len(pending):])
+	c.waiters = pending
+}
+
+// DelayHandle refers to an item scheduled in a DelayQueue. It is
+// returned by Push and passed back to Cancel.
+type DelayHandle[T any] struct {
+	value    T
+	deadline time.Time
+	seq      uint64 // insertion order, breaks ties between equal deadlines
+	index    int    // position in the heap, -1 once the item has left the queue
+}
+
+// Value returns the value stored in the item.
+func (h *DelayHandle[T]) Value() T {
+	return h.value
+}
+
+// Deadline returns the time the item becomes available.
+func (h *DelayHandle[T]) Deadline() time.Time {
+	return h.deadline
+}
+
+// delayHeap implements heap.Interface for DelayQueue, earliest deadline
+// first. Items with equal deadlines come out first-in, first-out.
+type delayHeap[T any] []*DelayHandle[T]
+
+func (h delayHeap[T]) Len() int { return len(h) }
+
+func (h delayHeap[T]) Less(i, j int) bool {
+	if !h[i].deadline.Equal(h[j].deadline) {
+		return h[i].deadline.Before(h[j].deadline)
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h delayHeap[T]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *delayHeap[T]) Push(x interface{}) {
+	item := x.(*DelayHandle[T])
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *delayHeap[T]) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil  // Avoid memory leak
+	item.index = -1 // For safety
+	*h = old[:n-1]
+	return item
+}
+
+// DelayQueue holds items that each become available at a deadline. Take
+// blocks until the earliest item is due, so a single goroutine can run
+// any number of retries or scheduled jobs without a timer per item. It is
+// safe for concurrent use. Create one with NewDelayQueue.
+type DelayQueue[T any] struct {
+	mu      sync.Mutex
+	items   delayHeap[T]
+	seq     uint64
+	clock   Clock
+	changed chan struct{} // closed and replaced when the earliest deadline changes
+}
+
+// NewDelayQueue returns an empty DelayQueue that reads the time from
+// clock, or from the time package if clock is nil.
+func NewDelayQueue[T any](clock Clock) *DelayQueue[T] {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &DelayQueue[T]{clock: clock, changed: make(chan struct{})}
+}
+
+// notify wakes every Take waiting on the old earliest deadline.
+// The caller must hold q.mu.
+func (q *DelayQueue[T]) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// Len returns the number of scheduled items, due or not.
+func (q *DelayQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Push schedules value to become available at deadline and returns a
+// handle that can cancel it.
+func (q *DelayQueue[T]) Push(value T, deadline time.Time) *DelayHandle[T] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item := &DelayHandle[T]{value: value, deadline: deadline, seq: q.seq}
+	q.seq++
+	heap.Push(&q.items, item)
+	if item.index == 0 {
+		q.notify()
+	}
+	return item
+}
+
+// PushAfter schedules value to become available after delay.
+func (q *DelayQueue[T]) PushAfter(value T, delay time.Duration) *DelayHandle[T] {
+	return q.Push(value, q.clock.Now().Add(delay))
+}
+
+// Cancel removes the item behind handle before it is taken.
+// Returns false if it was already taken or cancelled.
+func (q *DelayQueue[T]) Cancel(handle *DelayHandle[T]) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if handle == nil || handle.index < 0 || handle.index >= len(q.items) || q.items[handle.index] != handle {
+		return false
+	}
+	wasFirst := handle.index == 0
+	heap.Remove(&q.items, handle.index)
+	if wasFirst {
+		q.notify()
+	}
+	return true
+}
+
+// NextDeadline returns the deadline of the earliest item.
+// Returns false if the queue is empty.
+func (q *DelayQueue[T]) NextDeadline() (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return time.Time{}, false
+	}
+	return q.items[0].deadline, true
+}
+
+// popDue removes and returns the earliest item if it is due. Otherwise it
+// returns nil and the earliest deadline, or the zero time if the queue
+// is empty. The caller must hold q.mu.
+func (q *DelayQueue[T]) popDue() (*DelayHandle[T], time.Time) {
+	if len(q.items) == 0 {
+		return nil, time.Time{}
+	}
+	if first := q.items[0]; first.deadline.After(q.clock.Now()) {
+		return nil, first.deadline
+	}
+	return heap.Pop(&q.items).(*DelayHandle[T]), time.Time{}
+}
+
+// Poll removes and returns the earliest item if it is due, without
+// waiting. Returns false if no item is due.
+func (q *DelayQueue[T]) Poll() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if item, _ := q.popDue(); item != nil {
+		return item.value, true
+	}
+	var zero T
+	return zero, false
+}
+
+// Take removes and returns the earliest item, waiting until it is due.
+// Items pushed or cancelled while it waits are taken into account. It
+// returns ctx.Err() if ctx is done first.
+func (q *DelayQueue[T]) Take(ctx context.Context) (T, error) {
+	for {
+		q.mu.Lock()
+		item, deadline := q.popDue()
+		changed := q.changed
+		q.mu.Unlock()
+		if item != nil {
+			return item.value, nil
+		}
+
+		var due <-chan time.Time // nil, so it never fires, while the queue is empty
+		stop := func() {}
+		if !deadline.IsZero() {
+			due, stop = q.clock.At(deadline)
+		}
+		select {
+		case <-ctx.Done():
+			stop()
+			var zero T
+			return zero, ctx.Err()
+		case <-changed:
+		case <-due:
+		}
+		stop()
+	}
+}
+
+// Validate checks the heap order and that every handle's index matches
+// its position.
+func (q *DelayQueue[T]) Validate() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, item := range q.items {
+		if item.index != i {
+			return fmt.Errorf("%w: delay queue item %d has index %d", ErrInvariant, i, item.index)
+		}
+		if i > 0 && q.items.Less(i, (i-1)/2) {
+			return fmt.Errorf("%w: delay queue item %d is due before its parent", ErrInvariant, i)
+		}
+	}
+	return nil
+}
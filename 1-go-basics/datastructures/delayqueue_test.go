@@ -0,0 +1,177 @@
+package datastructures
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type takeResult struct {
+	value string
+	err   error
+}
+
+// startTake calls q.Take(ctx) in a goroutine and delivers its result on
+// the returned channel.
+func startTake(ctx context.Context, q *DelayQueue[string]) <-chan takeResult {
+	done := make(chan takeResult, 1)
+	go func() {
+		value, err := q.Take(ctx)
+		done <- takeResult{value, err}
+	}()
+	return done
+}
+
+// waitForWaiters waits until the clock's pending deadlines, as offsets
+// from epoch, are exactly want.
+func waitForWaiters(t *testing.T, clock *ManualClock, want ...time.Duration) {
+	t.Helper()
+	var got []time.Duration
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(time.Millisecond) {
+		clock.mu.Lock()
+		got = got[:0]
+		for _, w := range clock.waiters {
+			got = append(got, w.deadline.Sub(epoch))
+		}
+		clock.mu.Unlock()
+		if slices.Equal(got, want) {
+			return
+		}
+	}
+	t.Fatalf("clock waiters = %v, want %v", got, want)
+}
+
+func expectTaken(t *testing.T, done <-chan takeResult, want string) {
+	t.Helper()
+	select {
+	case r := <-done:
+		if r.err != nil || r.value != want {
+			t.Fatalf("Take = %q, %v, want %q", r.value, r.err, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Take did not return %q", want)
+	}
+}
+
+func expectWaiting(t *testing.T, done <-chan takeResult) {
+	t.Helper()
+	select {
+	case r := <-done:
+		t.Fatalf("Take returned %q, %v early", r.value, r.err)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestDelayQueueTakeEarlierPush(t *testing.T) {
+	clock := NewManualClock(epoch)
+	q := NewDelayQueue[string](clock)
+	q.PushAfter("late", 10*time.Second)
+	done := startTake(context.Background(), q)
+	waitForWaiters(t, clock, 10*time.Second)
+
+	q.PushAfter("early", time.Second)
+	// The wait for the old head is dropped, not left behind.
+	waitForWaiters(t, clock, time.Second)
+	clock.Advance(time.Second)
+	expectTaken(t, done, "early")
+	waitForWaiters(t, clock)
+
+	done = startTake(context.Background(), q)
+	waitForWaiters(t, clock, 10*time.Second)
+	clock.Advance(9 * time.Second)
+	expectTaken(t, done, "late")
+}
+
+func TestDelayQueueTakeEmpty(t *testing.T) {
+	clock := NewManualClock(epoch)
+	q := NewDelayQueue[string](clock)
+	done := startTake(context.Background(), q)
+	expectWaiting(t, done)
+	q.PushAfter("now", 0)
+	expectTaken(t, done, "now")
+}
+
+func TestDelayQueueTakeCancelledHead(t *testing.T) {
+	clock := NewManualClock(epoch)
+	q := NewDelayQueue[string](clock)
+	head := q.PushAfter("head", time.Second)
+	q.PushAfter("next", 5*time.Second)
+	done := startTake(context.Background(), q)
+	waitForWaiters(t, clock, time.Second)
+
+	if !q.Cancel(head) {
+		t.Fatal("Cancel(head) = false")
+	}
+	waitForWaiters(t, clock, 5*time.Second)
+	clock.Advance(time.Second)
+	expectWaiting(t, done)
+	clock.Advance(4 * time.Second)
+	expectTaken(t, done, "next")
+	if q.Cancel(head) {
+		t.Error("second Cancel(head) = true")
+	}
+}
+
+func TestDelayQueueTakeContext(t *testing.T) {
+	clock := NewManualClock(epoch)
+	q := NewDelayQueue[string](clock)
+	q.PushAfter("item", time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := startTake(ctx, q)
+	waitForWaiters(t, clock, time.Minute)
+	cancel()
+	select {
+	case r := <-done:
+		if !errors.Is(r.err, context.Canceled) {
+			t.Fatalf("Take = %q, %v, want context.Canceled", r.value, r.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Take did not return after cancel")
+	}
+	waitForWaiters(t, clock)
+	if q.Len() != 1 {
+		t.Errorf("Len = %d after a cancelled Take, want 1", q.Len())
+	}
+
+	expired, cancel := context.WithDeadline(context.Background(), epoch)
+	defer cancel()
+	if _, err := q.Take(expired); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Take with an expired context = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestDelayQueueEqualDeadlinesFIFO(t *testing.T) {
+	clock := NewManualClock(epoch)
+	q := NewDelayQueue[string](clock)
+	want := []string{"a", "b", "c", "d", "e", "f"}
+	for _, v := range want {
+		q.Push(v, epoch.Add(time.Second))
+	}
+	q.PushAfter("sooner", time.Millisecond)
+	if _, ok := q.Poll(); ok {
+		t.Fatal("Poll returned an item before it was due")
+	}
+	clock.Advance(time.Second)
+	if v, ok := q.Poll(); !ok || v != "sooner" {
+		t.Fatalf("Poll = %q, %v, want sooner", v, ok)
+	}
+	var got []string
+	for range want {
+		v, err := q.Take(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("equal deadlines came out as %v, want %v", got, want)
+	}
+	if err := q.Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,168 @@
+package datastructures
+
+import "fmt"
+
+// ======================================================
+// Deque Implementation (Growable Circular Buffer)
+// ======================================================
+
+// minDequeCapacity is the smallest backing array a non-empty Deque keeps.
+// Capacities are always powers of two so indexes wrap with a bit mask.
+const minDequeCapacity = 8
+
+// Deque is a double-ended queue backed by a circular buffer.
+// The buffer doubles when full and halves when it is a quarter full,
+// so all push and pop operations are amortized O(1) and memory is
+// returned after a burst. The zero value is an empty deque ready to use.
+type Deque[T any] struct {
+	buf  []T
+	head int // index of the front element
+	n    int // number of elements
+}
+
+// Len returns the number of items in the deque.
+func (d *Deque[T]) Len() int {
+	return d.n
+}
+
+// Cap returns the size of the backing buffer.
+func (d *Deque[T]) Cap() int {
+	return len(d.buf)
+}
+
+// IsEmpty returns true if the deque is empty.
+func (d *Deque[T]) IsEmpty() bool {
+	return d.n == 0
+}
+
+// PushBack adds an item to the back of the deque.
+func (d *Deque[T]) PushBack(item T) {
+	d.growIfFull()
+	d.buf[(d.head+d.n)&(len(d.buf)-1)] = item
+	d.n++
+}
+
+// PushFront adds an item to the front of the deque.
+func (d *Deque[T]) PushFront(item T) {
+	d.growIfFull()
+	d.head = (d.head - 1) & (len(d.buf) - 1)
+	d.buf[d.head] = item
+	d.n++
+}
+
+// PopFront removes and returns the item at the front. Returns false if empty.
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	item := d.buf[d.head]
+	d.buf[d.head] = zero // Release the reference for the garbage collector
+	d.head = (d.head + 1) & (len(d.buf) - 1)
+	d.n--
+	d.shrinkIfSparse()
+	return item, true
+}
+
+// PopBack removes and returns the item at the back. Returns false if empty.
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	tail := (d.head + d.n - 1) & (len(d.buf) - 1)
+	item := d.buf[tail]
+	d.buf[tail] = zero // Release the reference for the garbage collector
+	d.n--
+	d.shrinkIfSparse()
+	return item, true
+}
+
+// Front returns the item at the front without removing it. Returns false if empty.
+func (d *Deque[T]) Front() (T, bool) {
+	if d.n == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.head], true
+}
+
+// Back returns the item at the back without removing it. Returns false if empty.
+func (d *Deque[T]) Back() (T, bool) {
+	if d.n == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[(d.head+d.n-1)&(len(d.buf)-1)], true
+}
+
+// At returns the item at position i, where 0 is the front.
+// It panics if i is out of range, like indexing a slice.
+func (d *Deque[T]) At(i int) T {
+	d.checkIndex(i)
+	return d.buf[(d.head+i)&(len(d.buf)-1)]
+}
+
+// Set replaces the item at position i, where 0 is the front.
+// It panics if i is out of range, like indexing a slice.
+func (d *Deque[T]) Set(i int, item T) {
+	d.checkIndex(i)
+	d.buf[(d.head+i)&(len(d.buf)-1)] = item
+}
+
+// Clear removes all items and releases the backing buffer.
+func (d *Deque[T]) Clear() {
+	*d = Deque[T]{}
+}
+
+func (d *Deque[T]) checkIndex(i int) {
+	if i < 0 || i >= d.n {
+		panic(fmt.Sprintf("datastructures: deque index %d out of range [0:%d]", i, d.n))
+	}
+}
+
+func (d *Deque[T]) growIfFull() {
+	if d.n < len(d.buf) {
+		return
+	}
+	if len(d.buf) == 0 {
+		d.buf = make([]T, minDequeCapacity)
+		return
+	}
+	d.resize(len(d.buf) * 2)
+}
+
+func (d *Deque[T]) shrinkIfSparse() {
+	if len(d.buf) > minDequeCapacity && d.n <= len(d.buf)/4 {
+		d.resize(len(d.buf) / 2)
+	}
+}
+
+// resize copies the items into a new buffer of the given capacity,
+// unwrapping them so the front lands at index 0.
+func (d *Deque[T]) resize(capacity int) {
+	buf := make([]T, capacity)
+	if d.head+d.n <= len(d.buf) {
+		copy(buf, d.buf[d.head:d.head+d.n])
+	} else {
+		k := copy(buf, d.buf[d.head:])
+		copy(buf[k:], d.buf[:d.n-k])
+	}
+	d.buf = buf
+	d.head = 0
+}
+
+// Validate checks that the capacity is zero or a power of two no smaller
+// than the minimum, and that head and length fit in the buffer.
+func (d *Deque[T]) Validate() error {
+	c := len(d.buf)
+	switch {
+	case c != 0 && (c&(c-1) != 0 || c < minDequeCapacity):
+		return fmt.Errorf("%w: deque capacity %d is not a power of two of at least %d", ErrInvariant, c, minDequeCapacity)
+	case d.n < 0 || d.n > c:
+		return fmt.Errorf("%w: deque length %d out of range [0:%d]", ErrInvariant, d.n, c+1)
+	case d.head < 0 || (c == 0 && d.head != 0) || (c > 0 && d.head >= c):
+		return fmt.Errorf("%w: deque head %d out of range for capacity %d", ErrInvariant, d.head, c)
+	}
+	return nil
+}
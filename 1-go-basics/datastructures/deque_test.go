@@ -0,0 +1,53 @@
+package datastructures
+
+import "testing"
+
+// queueBacklog is the number of items kept in flight by the queue
+// benchmarks, like a long-running worker queue.
+const queueBacklog = 1024
+
+// sliceQueue is the original reslicing queue, kept only so the
+// benchmarks can compare it with the ring-buffer Queue.
+type sliceQueue[T any] struct {
+	items []T
+}
+
+func (q *sliceQueue[T]) Enqueue(item T) {
+	q.items = append(q.items, item)
+}
+
+func (q *sliceQueue[T]) Dequeue() (T, bool) {
+	if len(q.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	item := q.items[0]
+	q.items = q.items[1:]
+	return item, true
+}
+
+func BenchmarkSliceQueue(b *testing.B) {
+	b.ReportAllocs()
+	var q sliceQueue[int]
+	for i := 0; i < queueBacklog; i++ {
+		q.Enqueue(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Enqueue(i)
+		q.Dequeue()
+	}
+}
+
+func BenchmarkRingQueue(b *testing.B) {
+	b.ReportAllocs()
+	var q Queue[int]
+	for i := 0; i < queueBacklog; i++ {
+		q.Enqueue(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Enqueue(i)
+		q.Dequeue()
+	}
+}
@@ -0,0 +1,258 @@
+package datastructures
+
+import "fmt"
+
+// ======================================================
+// Disjoint Set Implementation (Union-Find)
+// ======================================================
+
+// DisjointSet partitions the dense integer IDs 0..Len()-1 into disjoint
+// sets. It uses union by rank and path compression, so Find and Union run
+// in near-constant amortized time. The zero value is an empty set; add
+// elements with Add or create a populated one with NewDisjointSet.
+type DisjointSet struct {
+	parent []int
+	rank   []uint8
+	size   []int // number of elements, valid for roots only
+	count  int   // number of components
+}
+
+// NewDisjointSet returns a DisjointSet of n singleton sets {0}, {1}, ... {n-1}.
+func NewDisjointSet(n int) *DisjointSet {
+	ds := &DisjointSet{}
+	for i := 0; i < n; i++ {
+		ds.Add()
+	}
+	return ds
+}
+
+// Add creates a new singleton set and returns its ID.
+func (ds *DisjointSet) Add() int {
+	id := len(ds.parent)
+	ds.parent = append(ds.parent, id)
+	ds.rank = append(ds.rank, 0)
+	ds.size = append(ds.size, 1)
+	ds.count++
+	return id
+}
+
+// Len returns the number of elements.
+func (ds *DisjointSet) Len() int {
+	return len(ds.parent)
+}
+
+// Count returns the number of disjoint components.
+func (ds *DisjointSet) Count() int {
+	return ds.count
+}
+
+// Find returns the representative of the set containing x.
+// It panics if x is not a valid ID, like indexing a slice.
+func (ds *DisjointSet) Find(x int) int {
+	root := x
+	for ds.parent[root] != root {
+		root = ds.parent[root]
+	}
+	// Path compression: point every node on the path at the root.
+	for ds.parent[x] != root {
+		ds.parent[x], x = root, ds.parent[x]
+	}
+	return root
+}
+
+// Union merges the sets containing a and b.
+// Returns false if they were already in the same set.
+func (ds *DisjointSet) Union(a, b int) bool {
+	ra, rb := ds.Find(a), ds.Find(b)
+	if ra == rb {
+		return false
+	}
+	// Union by rank: hang the shallower tree under the deeper one.
+	if ds.rank[ra] < ds.rank[rb] {
+		ra, rb = rb, ra
+	}
+	ds.parent[rb] = ra
+	ds.size[ra] += ds.size[rb]
+	if ds.rank[ra] == ds.rank[rb] {
+		ds.rank[ra]++
+	}
+	ds.count--
+	return true
+}
+
+// Connected returns true if a and b are in the same set.
+func (ds *DisjointSet) Connected(a, b int) bool {
+	return ds.Find(a) == ds.Find(b)
+}
+
+// SizeOf returns the number of elements in the set containing x.
+func (ds *DisjointSet) SizeOf(x int) int {
+	return ds.size[ds.Find(x)]
+}
+
+// Components returns every set as a slice of IDs. Sets are ordered by
+// their smallest ID and IDs within a set are ascending.
+func (ds *DisjointSet) Components() [][]int {
+	slot := make(map[int]int, ds.count) // root -> index in components
+	components := make([][]int, 0, ds.count)
+	for x := range ds.parent {
+		root := ds.Find(x)
+		i, ok := slot[root]
+		if !ok {
+			i = len(components)
+			slot[root] = i
+			components = append(components, make([]int, 0, ds.size[root]))
+		}
+		components[i] = append(components[i], x)
+	}
+	return components
+}
+
+// Validate checks that parents are in range, that ranks strictly increase
+// toward each root, which rules out cycles, and that the component count
+// and the size of every set are correct.
+func (ds *DisjointSet) Validate() error {
+	n := len(ds.parent)
+	if len(ds.rank) != n || len(ds.size) != n {
+		return fmt.Errorf("%w: disjoint set has %d parents, %d ranks and %d sizes", ErrInvariant, n, len(ds.rank), len(ds.size))
+	}
+	roots := 0
+	for x, p := range ds.parent {
+		switch {
+		case p < 0 || p >= n:
+			return fmt.Errorf("%w: disjoint set element %d has parent %d", ErrInvariant, x, p)
+		case p == x:
+			roots++
+		case ds.rank[p] <= ds.rank[x]:
+			return fmt.Errorf("%w: disjoint set element %d has rank %d, parent %d has rank %d", ErrInvariant, x, ds.rank[x], p, ds.rank[p])
+		}
+	}
+	if roots != ds.count {
+		return fmt.Errorf("%w: disjoint set has %d components but count %d", ErrInvariant, roots, ds.count)
+	}
+	members := make(map[int]int, roots)
+	for x := range ds.parent {
+		root := x
+		for ds.parent[root] != root {
+			root = ds.parent[root]
+		}
+		members[root]++
+	}
+	for root, m := range members {
+		if ds.size[root] != m {
+			return fmt.Errorf("%w: disjoint set %d has %d elements but size %d", ErrInvariant, root, m, ds.size[root])
+		}
+	}
+	return nil
+}
+
+// KeyedDisjointSet is a DisjointSet over arbitrary comparable keys.
+// Keys are mapped to dense IDs as they are first seen.
+// Create one with NewKeyedDisjointSet.
+type KeyedDisjointSet[K comparable] struct {
+	set  DisjointSet
+	ids  map[K]int
+	keys []K // id -> key
+}
+
+// NewKeyedDisjointSet returns an empty KeyedDisjointSet.
+func NewKeyedDisjointSet[K comparable]() *KeyedDisjointSet[K] {
+	return &KeyedDisjointSet[K]{ids: make(map[K]int)}
+}
+
+// id returns the ID of key, adding it as a singleton if needed.
+func (k *KeyedDisjointSet[K]) id(key K) int {
+	if id, ok := k.ids[key]; ok {
+		return id
+	}
+	id := k.set.Add()
+	k.ids[key] = id
+	k.keys = append(k.keys, key)
+	return id
+}
+
+// Add adds key as a singleton set if it is not already present.
+func (k *KeyedDisjointSet[K]) Add(key K) {
+	k.id(key)
+}
+
+// Contains returns true if key has been added.
+func (k *KeyedDisjointSet[K]) Contains(key K) bool {
+	_, ok := k.ids[key]
+	return ok
+}
+
+// Len returns the number of keys.
+func (k *KeyedDisjointSet[K]) Len() int {
+	return k.set.Len()
+}
+
+// Count returns the number of disjoint components.
+func (k *KeyedDisjointSet[K]) Count() int {
+	return k.set.Count()
+}
+
+// Find returns the representative key of the set containing key.
+// Returns false if key has not been added.
+func (k *KeyedDisjointSet[K]) Find(key K) (K, bool) {
+	id, ok := k.ids[key]
+	if !ok {
+		var zero K
+		return zero, false
+	}
+	return k.keys[k.set.Find(id)], true
+}
+
+// Union merges the sets containing a and b, adding either key if it is
+// missing. Returns false if they were already in the same set.
+func (k *KeyedDisjointSet[K]) Union(a, b K) bool {
+	return k.set.Union(k.id(a), k.id(b))
+}
+
+// Connected returns true if a and b have been added and are in the same set.
+func (k *KeyedDisjointSet[K]) Connected(a, b K) bool {
+	ia, okA := k.ids[a]
+	ib, okB := k.ids[b]
+	return okA && okB && k.set.Connected(ia, ib)
+}
+
+// SizeOf returns the number of keys in the set containing key,
+// or 0 if key has not been added.
+func (k *KeyedDisjointSet[K]) SizeOf(key K) int {
+	id, ok := k.ids[key]
+	if !ok {
+		return 0
+	}
+	return k.set.SizeOf(id)
+}
+
+// Components returns every set as a slice of keys, ordered by when the
+// first key of each set was added.
+func (k *KeyedDisjointSet[K]) Components() [][]K {
+	components := make([][]K, 0, k.set.Count())
+	for _, ids := range k.set.Components() {
+		keys := make([]K, len(ids))
+		for i, id := range ids {
+			keys[i] = k.keys[id]
+		}
+		components = append(components, keys)
+	}
+	return components
+}
+
+// Validate checks the underlying DisjointSet and that keys and IDs map
+// to each other one to one.
+func (k *KeyedDisjointSet[K]) Validate() error {
+	if err := k.set.Validate(); err != nil {
+		return err
+	}
+	if len(k.keys) != k.set.Len() || len(k.ids) != len(k.keys) {
+		return fmt.Errorf("%w: keyed disjoint set has %d keys, %d IDs and %d elements", ErrInvariant, len(k.keys), len(k.ids), k.set.Len())
+	}
+	for id, key := range k.keys {
+		if k.ids[key] != id {
+			return fmt.Errorf("%w: keyed disjoint set key %v has ID %d, want %d", ErrInvariant, key, k.ids[key], id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package datastructures
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ======================================================
+// Doubly Linked List
+// ======================================================
+
+// DoublyListNode is a node of a DoublyLinkedList. The node pointers
+// returned by the list act as handles: they stay valid until the node is
+// removed and allow O(1) removal and insertion next to it.
+type DoublyListNode[T any] struct {
+	Data T
+	next *DoublyListNode[T]
+	prev *DoublyListNode[T]
+	list *DoublyLinkedList[T] // nil once removed
+}
+
+// Next returns the following node, or nil at the back of the list.
+func (n *DoublyListNode[T]) Next() *DoublyListNode[T] {
+	return n.next
+}
+
+// Prev returns the preceding node, or nil at the front of the list.
+func (n *DoublyListNode[T]) Prev() *DoublyListNode[T] {
+	return n.prev
+}
+
+// DoublyLinkedList is a list of values of type T linked in both
+// directions. The zero value is an empty list.
+type DoublyLinkedList[T any] struct {
+	head   *DoublyListNode[T]
+	tail   *DoublyListNode[T]
+	length int
+}
+
+// Len returns the number of nodes in the list.
+func (l *DoublyLinkedList[T]) Len() int {
+	return l.length
+}
+
+// Front returns the first node, or nil if the list is empty.
+func (l *DoublyLinkedList[T]) Front() *DoublyListNode[T] {
+	return l.head
+}
+
+// Back returns the last node, or nil if the list is empty.
+func (l *DoublyLinkedList[T]) Back() *DoublyListNode[T] {
+	return l.tail
+}
+
+// link inserts n between prev and next, either of which may be nil.
+func (l *DoublyLinkedList[T]) link(n, prev, next *DoublyListNode[T]) *DoublyListNode[T] {
+	n.prev, n.next, n.list = prev, next, l
+	if prev == nil {
+		l.head = n
+	} else {
+		prev.next = n
+	}
+	if next == nil {
+		l.tail = n
+	} else {
+		next.prev = n
+	}
+	l.length++
+	return n
+}
+
+func (l *DoublyLinkedList[T]) unlink(n *DoublyListNode[T]) {
+	if n.prev == nil {
+		l.head = n.next
+	} else {
+		n.prev.next = n.next
+	}
+	if n.next == nil {
+		l.tail = n.prev
+	} else {
+		n.next.prev = n.prev
+	}
+	n.prev, n.next, n.list = nil, nil, nil
+	l.length--
+}
+
+// owns panics if n is not a node of l, since linking through a foreign
+// node would corrupt both lists.
+func (l *DoublyLinkedList[T]) owns(n *DoublyListNode[T]) {
+	if n == nil || n.list != l {
+		panic("datastructures: node does not belong to this list")
+	}
+}
+
+// PushFront adds data at the front of the list and returns its node.
+func (l *DoublyLinkedList[T]) PushFront(data T) *DoublyListNode[T] {
+	return l.link(&DoublyListNode[T]{Data: data}, nil, l.head)
+}
+
+// PushBack adds data at the back of the list and returns its node.
+func (l *DoublyLinkedList[T]) PushBack(data T) *DoublyListNode[T] {
+	return l.link(&DoublyListNode[T]{Data: data}, l.tail, nil)
+}
+
+// InsertBefore adds data just before mark and returns its node.
+// It panics if mark is not in l.
+func (l *DoublyLinkedList[T]) InsertBefore(data T, mark *DoublyListNode[T]) *DoublyListNode[T] {
+	l.owns(mark)
+	return l.link(&DoublyListNode[T]{Data: data}, mark.prev, mark)
+}
+
+// InsertAfter adds data just after mark and returns its node.
+// It panics if mark is not in l.
+func (l *DoublyLinkedList[T]) InsertAfter(data T, mark *DoublyListNode[T]) *DoublyListNode[T] {
+	l.owns(mark)
+	return l.link(&DoublyListNode[T]{Data: data}, mark, mark.next)
+}
+
+// Remove unlinks n from the list in O(1). Returns false if n is nil or
+// not in l, for example because it was already removed.
+func (l *DoublyLinkedList[T]) Remove(n *DoublyListNode[T]) bool {
+	if n == nil || n.list != l {
+		return false
+	}
+	l.unlink(n)
+	return true
+}
+
+// PopFront removes and returns the first value. Returns false if empty.
+func (l *DoublyLinkedList[T]) PopFront() (T, bool) {
+	if l.head == nil {
+		var zero T
+		return zero, false
+	}
+	n := l.head
+	l.unlink(n)
+	return n.Data, true
+}
+
+// PopBack removes and returns the last value. Returns false if empty.
+func (l *DoublyLinkedList[T]) PopBack() (T, bool) {
+	if l.tail == nil {
+		var zero T
+		return zero, false
+	}
+	n := l.tail
+	l.unlink(n)
+	return n.Data, true
+}
+
+// MoveToFront moves n to the front of the list. It panics if n is not in l.
+func (l *DoublyLinkedList[T]) MoveToFront(n *DoublyListNode[T]) {
+	l.owns(n)
+	if l.head != n {
+		l.unlink(n)
+		l.link(n, nil, l.head)
+	}
+}
+
+// MoveToBack moves n to the back of the list. It panics if n is not in l.
+func (l *DoublyLinkedList[T]) MoveToBack(n *DoublyListNode[T]) {
+	l.owns(n)
+	if l.tail != n {
+		l.unlink(n)
+		l.link(n, l.tail, nil)
+	}
+}
+
+// Reverse reverses the list in place. Node handles stay valid.
+func (l *DoublyLinkedList[T]) Reverse() {
+	for n := l.head; n != nil; n = n.prev {
+		n.next, n.prev = n.prev, n.next
+	}
+	l.head, l.tail = l.tail, l.head
+}
+
+// Values returns the values from front to back.
+func (l *DoublyLinkedList[T]) Values() []T {
+	values := make([]T, 0, l.length)
+	for n := l.head; n != nil; n = n.next {
+		values = append(values, n.Data)
+	}
+	return values
+}
+
+// String formats the list as "nil <-> a <-> b <-> nil".
+func (l *DoublyLinkedList[T]) String() string {
+	var sb strings.Builder
+	sb.WriteString("nil")
+	for n := l.head; n != nil; n = n.next {
+		fmt.Fprintf(&sb, " <-> %v", n.Data)
+	}
+	sb.WriteString(" <-> nil")
+	return sb.String()
+}
+
+// Validate checks that the prev links mirror the next links, that every
+// node belongs to l, and that the cached length and tail match the nodes.
+func (l *DoublyLinkedList[T]) Validate() error {
+	count := 0
+	var prev *DoublyListNode[T]
+	for n := l.head; n != nil; n = n.next {
+		if count == l.length {
+			return fmt.Errorf("%w: doubly linked list has more than %d nodes", ErrInvariant, l.length)
+		}
+		if n.list != l {
+			return fmt.Errorf("%w: doubly linked list node %d belongs to another list", ErrInvariant, count)
+		}
+		if n.prev != prev {
+			return fmt.Errorf("%w: doubly linked list node %d has a wrong prev link", ErrInvariant, count)
+		}
+		prev = n
+		count++
+	}
+	if count != l.length {
+		return fmt.Errorf("%w: doubly linked list has %d nodes but length %d", ErrInvariant, count, l.length)
+	}
+	if prev != l.tail {
+		return fmt.Errorf("%w: doubly linked list tail is not its last node", ErrInvariant)
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+package datastructures
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+// ======================================================
+// JSON and gob Encoding
+// ======================================================
+//
+// Each structure encodes as a flat list in an order that rebuilds it
+// exactly:
+//
+//	LinkedList       head to tail           [1,2,3]
+//	Stack            bottom to top          [1,2,3]
+//	Queue            front to back          [1,2,3]
+//	BinarySearchTree pre-order              [5,3,8]
+//	PriorityQueue    heap array order       [{"value":"a","priority":1}]
+//
+// The gob form carries the same list. Decoding replaces the receiver's
+// contents and rejects input that breaks the structure's invariants.
+// A JSON null decodes to an empty structure.
+
+func gobEncode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gobDecode(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// ----- LinkedList -----
+
+func (l LinkedList[T]) items() []T {
+	items := []T{}
+	for current := l.Head; current != nil; current = current.Next {
+		items = append(items, current.Data)
+	}
+	return items
+}
+
+func (l *LinkedList[T]) setItems(items []T) {
+	*l = LinkedList[T]{}
+	for _, item := range items {
+		l.Append(item)
+	}
+}
+
+// MarshalJSON encodes the list as a JSON array from head to tail.
+func (l LinkedList[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.items())
+}
+
+// UnmarshalJSON replaces the list with the elements of a JSON array.
+func (l *LinkedList[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("datastructures: decoding LinkedList: %w", err)
+	}
+	l.setItems(items)
+	return nil
+}
+
+// GobEncode encodes the list from head to tail.
+func (l LinkedList[T]) GobEncode() ([]byte, error) {
+	return gobEncode(l.items())
+}
+
+// GobDecode replaces the list with the gob-encoded elements.
+func (l *LinkedList[T]) GobDecode(data []byte) error {
+	var items []T
+	if err := gobDecode(data, &items); err != nil {
+		return fmt.Errorf("datastructures: decoding LinkedList: %w", err)
+	}
+	l.setItems(items)
+	return nil
+}
+
+// ----- Stack -----
+
+// MarshalJSON encodes the stack as a JSON array from bottom to top.
+func (s Stack[T]) MarshalJSON() ([]byte, error) {
+	if s.items == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s.items)
+}
+
+// UnmarshalJSON replaces the stack with the elements of a JSON array,
+// the last element becoming the top.
+func (s *Stack[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("datastructures: decoding Stack: %w", err)
+	}
+	s.items = items
+	return nil
+}
+
+// GobEncode encodes the stack from bottom to top.
+func (s Stack[T]) GobEncode() ([]byte, error) {
+	return gobEncode(s.items)
+}
+
+// GobDecode replaces the stack with the gob-encoded elements.
+func (s *Stack[T]) GobDecode(data []byte) error {
+	var items []T
+	if err := gobDecode(data, &items); err != nil {
+		return fmt.Errorf("datastructures: decoding Stack: %w", err)
+	}
+	s.items = items
+	return nil
+}
+
+// ----- Queue -----
+
+func (q Queue[T]) slice() []T {
+	items := make([]T, q.items.Len())
+	for i := range items {
+		items[i] = q.items.At(i)
+	}
+	return items
+}
+
+func (q *Queue[T]) setItems(items []T) {
+	q.items.Clear()
+	for _, item := range items {
+		q.items.PushBack(item)
+	}
+}
+
+// MarshalJSON encodes the queue as a JSON array from front to back.
+func (q Queue[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.slice())
+}
+
+// UnmarshalJSON replaces the queue with the elements of a JSON array,
+// the first element becoming the front.
+func (q *Queue[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("datastructures: decoding Queue: %w", err)
+	}
+	q.setItems(items)
+	return nil
+}
+
+// GobEncode encodes the queue from front to back.
+func (q Queue[T]) GobEncode() ([]byte, error) {
+	return gobEncode(q.slice())
+}
+
+// GobDecode replaces the queue with the gob-encoded elements.
+func (q *Queue[T]) GobDecode(data []byte) error {
+	var items []T
+	if err := gobDecode(data, &items); err != nil {
+		return fmt.Errorf("datastructures: decoding Queue: %w", err)
+	}
+	q.setItems(items)
+	return nil
+}
+
+// ----- BinarySearchTree -----
+
+// preOrder returns the values of the tree in pre-order. Inserting them
+// in that order into an empty tree rebuilds the same shape.
+func (bst BinarySearchTree) preOrder() []int {
+	values := []int{}
+	stack := []*TreeNode{}
+	if bst.Root != nil {
+		stack = append(stack, bst.Root)
+	}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		values = append(values, node.Data)
+		if node.Right != nil {
+			stack = append(stack, node.Right)
+		}
+		if node.Left != nil {
+			stack = append(stack, node.Left)
+		}
+	}
+	return values
+}
+
+// treeFromPreOrder rebuilds a tree from its pre-order values in O(n),
+// following Insert's rule that smaller values go left and equal or
+// larger values go right. It rejects sequences no such tree produces.
+func treeFromPreOrder(values []int) (*TreeNode, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	root := &TreeNode{Data: values[0]}
+	path := []*TreeNode{root} // Nodes whose right subtree is still open
+	hasLower, lower := false, 0
+	for i, v := range values[1:] {
+		if hasLower && v < lower {
+			return nil, fmt.Errorf("value %d at index %d must be >= %d to follow the values before it", v, i+1, lower)
+		}
+		node := &TreeNode{Data: v}
+		var parent *TreeNode
+		for len(path) > 0 && path[len(path)-1].Data <= v {
+			parent = path[len(path)-1]
+			path = path[:len(path)-1]
+		}
+		if parent != nil {
+			parent.Right = node
+			hasLower, lower = true, parent.Data
+		} else {
+			path[len(path)-1].Left = node
+		}
+		path = append(path, node)
+	}
+	return root, nil
+}
+
+// MarshalJSON encodes the tree as a JSON array of its values in pre-order.
+func (bst BinarySearchTree) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bst.preOrder())
+}
+
+// UnmarshalJSON rebuilds the tree from a pre-order JSON array.
+func (bst *BinarySearchTree) UnmarshalJSON(data []byte) error {
+	var values []int
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("datastructures: decoding BinarySearchTree: %w", err)
+	}
+	root, err := treeFromPreOrder(values)
+	if err != nil {
+		return fmt.Errorf("datastructures: decoding BinarySearchTree: %w", err)
+	}
+	bst.Root = root
+	return nil
+}
+
+// GobEncode encodes the tree's values in pre-order.
+func (bst BinarySearchTree) GobEncode() ([]byte, error) {
+	return gobEncode(bst.preOrder())
+}
+
+// GobDecode rebuilds the tree from gob-encoded pre-order values.
+func (bst *BinarySearchTree) GobDecode(data []byte) error {
+	var values []int
+	if err := gobDecode(data, &values); err != nil {
+		return fmt.Errorf("datastructures: decoding BinarySearchTree: %w", err)
+	}
+	root, err := treeFromPreOrder(values)
+	if err != nil {
+		return fmt.Errorf("datastructures: decoding BinarySearchTree: %w", err)
+	}
+	bst.Root = root
+	return nil
+}
+
+// ----- PriorityQueue -----
+
+// priorityQueueEntry is the encoded form of a PriorityQueueItem. Index is
+// implied by the entry's position.
+type priorityQueueEntry struct {
+	Value    interface{} `json:"value"`
+	Priority int         `json:"priority"`
+}
+
+func (pq PriorityQueue) entries() ([]priorityQueueEntry, error) {
+	entries := make([]priorityQueueEntry, len(pq))
+	for i, item := range pq {
+		if item == nil {
+			return nil, fmt.Errorf("datastructures: encoding PriorityQueue: item %d is nil", i)
+		}
+		entries[i] = priorityQueueEntry{Value: item.Value, Priority: item.Priority}
+	}
+	return entries, nil
+}
+
+// priorityQueueJSONEntry is decoded from JSON before conversion to a
+// priorityQueueEntry. Priority is a pointer so a missing field is an
+// error rather than a silent zero.
+type priorityQueueJSONEntry struct {
+	Value    interface{} `json:"value"`
+	Priority *int        `json:"priority"`
+}
+
+// setEntries replaces pq with entries after checking that they satisfy
+// the heap order.
+func (pq *PriorityQueue) setEntries(entries []priorityQueueEntry) error {
+	items := make(PriorityQueue, len(entries))
+	for i, e := range entries {
+		items[i] = &PriorityQueueItem{Value: e.Value, Priority: e.Priority, Index: i}
+		if parent := (i - 1) / 2; i > 0 && items.Less(i, parent) {
+			return fmt.Errorf("item %d (priority %d) is ahead of its parent %d (priority %d); heap order is broken",
+				i, items[i].Priority, parent, items[parent].Priority)
+		}
+	}
+	*pq = items
+	return nil
+}
+
+// MarshalJSON encodes the queue as a JSON array of {"value", "priority"}
+// objects in heap array order, so decoding needs no re-heapify.
+func (pq PriorityQueue) MarshalJSON() ([]byte, error) {
+	entries, err := pq.entries()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON replaces the queue with the items of a JSON array in heap
+// order. Values decode to the generic JSON types (float64, string,
+// map[string]interface{}, ...).
+func (pq *PriorityQueue) UnmarshalJSON(data []byte) error {
+	var raw []priorityQueueJSONEntry
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&raw); err != nil {
+		return fmt.Errorf("datastructures: decoding PriorityQueue: %w", err)
+	}
+	entries := make([]priorityQueueEntry, len(raw))
+	for i, r := range raw {
+		if r.Priority == nil {
+			return fmt.Errorf("datastructures: decoding PriorityQueue: item %d has no priority", i)
+		}
+		entries[i] = priorityQueueEntry{Value: r.Value, Priority: *r.Priority}
+	}
+	if err := pq.setEntries(entries); err != nil {
+		return fmt.Errorf("datastructures: decoding PriorityQueue: %w", err)
+	}
+	return nil
+}
+
+// GobEncode encodes the items in heap order. Concrete value types other
+// than the basic ones must be registered with gob.Register.
+func (pq PriorityQueue) GobEncode() ([]byte, error) {
+	entries, err := pq.entries()
+	if err != nil {
+		return nil, err
+	}
+	return gobEncode(entries)
+}
+
+// GobDecode replaces the queue with gob-encoded items in heap order.
+func (pq *PriorityQueue) GobDecode(data []byte) error {
+	var entries []priorityQueueEntry
+	if err := gobDecode(data, &entries); err != nil {
+		return fmt.Errorf("datastructures: decoding PriorityQueue: %w", err)
+	}
+	if err := pq.setEntries(entries); err != nil {
+		return fmt.Errorf("datastructures: decoding PriorityQueue: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,466 @@
+package datastructures
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ======================================================
+// Graph Implementation (Adjacency List)
+// ======================================================
+
+// Errors returned by Graph algorithms.
+var (
+	ErrNodeNotFound   = errors.New("graph: node not found")
+	ErrNegativeWeight = errors.New("graph: negative edge weight")
+	ErrNegativeCycle  = errors.New("graph: negative cycle reachable from source")
+	ErrCycle          = errors.New("graph: graph contains a cycle")
+	ErrDirected       = errors.New("graph: operation requires an undirected graph")
+	ErrUndirected     = errors.New("graph: operation requires a directed graph")
+	ErrNotConnected   = errors.New("graph: graph is not connected")
+)
+
+// Edge is a weighted edge between two nodes.
+type Edge[N comparable] struct {
+	From   N
+	To     N
+	Weight int
+}
+
+// Graph is a directed or undirected graph with weighted edges, stored as
+// adjacency lists. Nodes and edges are kept in insertion order so every
+// traversal is deterministic. Create one with NewDirectedGraph or
+// NewUndirectedGraph.
+type Graph[N comparable] struct {
+	directed bool
+	nodes    []N
+	index    map[N]int   // node -> position in nodes
+	adj      [][]Edge[N] // outgoing edges, indexed like nodes
+}
+
+// NewDirectedGraph returns an empty directed graph.
+func NewDirectedGraph[N comparable]() *Graph[N] {
+	return &Graph[N]{directed: true, index: make(map[N]int)}
+}
+
+// NewUndirectedGraph returns an empty undirected graph.
+func NewUndirectedGraph[N comparable]() *Graph[N] {
+	return &Graph[N]{index: make(map[N]int)}
+}
+
+// Directed returns true if the graph is directed.
+func (g *Graph[N]) Directed() bool {
+	return g.directed
+}
+
+// AddNode adds n to the graph if it is not already present.
+func (g *Graph[N]) AddNode(n N) {
+	g.id(n)
+}
+
+// id returns the position of n, adding the node if needed.
+func (g *Graph[N]) id(n N) int {
+	if i, ok := g.index[n]; ok {
+		return i
+	}
+	g.index[n] = len(g.nodes)
+	g.nodes = append(g.nodes, n)
+	g.adj = append(g.adj, nil)
+	return len(g.nodes) - 1
+}
+
+// AddEdge adds an edge with the given weight, adding missing nodes.
+// In an undirected graph the edge can be traversed both ways.
+func (g *Graph[N]) AddEdge(from, to N, weight int) {
+	f, t := g.id(from), g.id(to)
+	g.adj[f] = append(g.adj[f], Edge[N]{From: from, To: to, Weight: weight})
+	if !g.directed && f != t {
+		g.adj[t] = append(g.adj[t], Edge[N]{From: to, To: from, Weight: weight})
+	}
+}
+
+// HasNode returns true if n is in the graph.
+func (g *Graph[N]) HasNode(n N) bool {
+	_, ok := g.index[n]
+	return ok
+}
+
+// HasEdge returns true if there is an edge from one node to the other.
+func (g *Graph[N]) HasEdge(from, to N) bool {
+	f, ok := g.index[from]
+	if !ok {
+		return false
+	}
+	for _, e := range g.adj[f] {
+		if e.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Nodes returns all nodes in insertion order.
+func (g *Graph[N]) Nodes() []N {
+	return append([]N(nil), g.nodes...)
+}
+
+// Neighbors returns the outgoing edges of n.
+func (g *Graph[N]) Neighbors(n N) []Edge[N] {
+	i, ok := g.index[n]
+	if !ok {
+		return nil
+	}
+	return append([]Edge[N](nil), g.adj[i]...)
+}
+
+// Edges returns every edge once. In an undirected graph each edge is
+// reported from whichever endpoint was added to the graph first.
+func (g *Graph[N]) Edges() []Edge[N] {
+	var edges []Edge[N]
+	for i, list := range g.adj {
+		for _, e := range list {
+			if !g.directed && g.index[e.To] < i {
+				continue // Reported from the other endpoint
+			}
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// BFS visits nodes reachable from start in breadth-first order.
+// Traversal stops early if visit returns false.
+func (g *Graph[N]) BFS(start N, visit func(N) bool) error {
+	s, ok := g.index[start]
+	if !ok {
+		return ErrNodeNotFound
+	}
+	seen := make([]bool, len(g.nodes))
+	seen[s] = true
+	var queue Queue[int]
+	queue.Enqueue(s)
+	for !queue.IsEmpty() {
+		u, _ := queue.Dequeue()
+		if !visit(g.nodes[u]) {
+			return nil
+		}
+		for _, e := range g.adj[u] {
+			v := g.index[e.To]
+			if !seen[v] {
+				seen[v] = true
+				queue.Enqueue(v)
+			}
+		}
+	}
+	return nil
+}
+
+// DFS visits nodes reachable from start in depth-first preorder,
+// following edges in insertion order. Traversal stops early if visit
+// returns false.
+func (g *Graph[N]) DFS(start N, visit func(N) bool) error {
+	s, ok := g.index[start]
+	if !ok {
+		return ErrNodeNotFound
+	}
+	seen := make([]bool, len(g.nodes))
+	var stack Stack[int]
+	stack.Push(s)
+	for !stack.IsEmpty() {
+		u, _ := stack.Pop()
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		if !visit(g.nodes[u]) {
+			return nil
+		}
+		// Push in reverse so the first edge is explored first.
+		for i := len(g.adj[u]) - 1; i >= 0; i-- {
+			if v := g.index[g.adj[u][i].To]; !seen[v] {
+				stack.Push(v)
+			}
+		}
+	}
+	return nil
+}
+
+// ShortestPaths holds single-source shortest path results.
+// Unreachable nodes are absent from Dist.
+type ShortestPaths[N comparable] struct {
+	Source N
+	Dist   map[N]int
+	Prev   map[N]N
+}
+
+// PathTo returns the nodes on the shortest path from the source to target
+// and its total weight. Returns false if target is unreachable.
+func (sp *ShortestPaths[N]) PathTo(target N) ([]N, int, bool) {
+	dist, ok := sp.Dist[target]
+	if !ok {
+		return nil, 0, false
+	}
+	path := []N{target}
+	for n := target; n != sp.Source; {
+		n = sp.Prev[n]
+		path = append(path, n)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, dist, true
+}
+
+// Dijkstra computes shortest paths from source. It uses PriorityQueue
+// with Update (heap.Fix) as the decrease-key operation and rejects
+// negative weights; use BellmanFord for those.
+func (g *Graph[N]) Dijkstra(source N) (*ShortestPaths[N], error) {
+	s, ok := g.index[source]
+	if !ok {
+		return nil, ErrNodeNotFound
+	}
+	for _, list := range g.adj {
+		for _, e := range list {
+			if e.Weight < 0 {
+				return nil, ErrNegativeWeight
+			}
+		}
+	}
+
+	sp := &ShortestPaths[N]{Source: source, Dist: make(map[N]int), Prev: make(map[N]N)}
+	items := make([]*PriorityQueueItem, len(g.nodes))
+	done := make([]bool, len(g.nodes))
+	pq := PriorityQueue{}
+	items[s] = &PriorityQueueItem{Value: s, Priority: 0}
+	heap.Push(&pq, items[s])
+	for pq.Len() > 0 {
+		item := heap.Pop(&pq).(*PriorityQueueItem)
+		u := item.Value.(int)
+		done[u] = true
+		sp.Dist[g.nodes[u]] = item.Priority
+		for _, e := range g.adj[u] {
+			v := g.index[e.To]
+			if done[v] {
+				continue
+			}
+			d := item.Priority + e.Weight
+			switch {
+			case items[v] == nil:
+				items[v] = &PriorityQueueItem{Value: v, Priority: d}
+				heap.Push(&pq, items[v])
+			case d < items[v].Priority:
+				pq.Update(items[v], v, d)
+			default:
+				continue
+			}
+			sp.Prev[e.To] = g.nodes[u]
+		}
+	}
+	return sp, nil
+}
+
+// BellmanFord computes shortest paths from source and allows negative
+// weights. Returns ErrNegativeCycle if a negative cycle is reachable.
+func (g *Graph[N]) BellmanFord(source N) (*ShortestPaths[N], error) {
+	if _, ok := g.index[source]; !ok {
+		return nil, ErrNodeNotFound
+	}
+	sp := &ShortestPaths[N]{Source: source, Dist: map[N]int{source: 0}, Prev: make(map[N]N)}
+	relax := func() bool {
+		changed := false
+		for _, list := range g.adj {
+			for _, e := range list {
+				du, ok := sp.Dist[e.From]
+				if !ok {
+					continue
+				}
+				if dv, ok := sp.Dist[e.To]; !ok || du+e.Weight < dv {
+					sp.Dist[e.To] = du + e.Weight
+					sp.Prev[e.To] = e.From
+					changed = true
+				}
+			}
+		}
+		return changed
+	}
+	for i := 1; i < len(g.nodes); i++ {
+		if !relax() {
+			return sp, nil
+		}
+	}
+	if relax() {
+		return nil, ErrNegativeCycle
+	}
+	return sp, nil
+}
+
+// TopologicalSort orders the nodes of a directed graph so every edge
+// points forward (Kahn's algorithm). Returns ErrCycle if there is none.
+func (g *Graph[N]) TopologicalSort() ([]N, error) {
+	if !g.directed {
+		return nil, ErrUndirected
+	}
+	indegree := make([]int, len(g.nodes))
+	for _, list := range g.adj {
+		for _, e := range list {
+			indegree[g.index[e.To]]++
+		}
+	}
+	var ready Queue[int]
+	for i, d := range indegree {
+		if d == 0 {
+			ready.Enqueue(i)
+		}
+	}
+	order := make([]N, 0, len(g.nodes))
+	for !ready.IsEmpty() {
+		u, _ := ready.Dequeue()
+		order = append(order, g.nodes[u])
+		for _, e := range g.adj[u] {
+			v := g.index[e.To]
+			indegree[v]--
+			if indegree[v] == 0 {
+				ready.Enqueue(v)
+			}
+		}
+	}
+	if len(order) != len(g.nodes) {
+		return nil, ErrCycle
+	}
+	return order, nil
+}
+
+// HasCycle returns true if the graph contains a cycle. In an undirected
+// graph a single edge is not a cycle, but a self-loop or a repeated edge is.
+func (g *Graph[N]) HasCycle() bool {
+	if g.directed {
+		_, err := g.TopologicalSort()
+		return err != nil
+	}
+	ds := NewDisjointSet(len(g.nodes))
+	for _, e := range g.Edges() {
+		if !ds.Union(g.index[e.From], g.index[e.To]) {
+			return true
+		}
+	}
+	return false
+}
+
+// StronglyConnectedComponents returns the strongly connected components
+// of a directed graph using Tarjan's algorithm. In an undirected graph
+// it returns the connected components.
+func (g *Graph[N]) StronglyConnectedComponents() [][]N {
+	n := len(g.nodes)
+	index := make([]int, n)
+	lowlink := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+	var stack Stack[int]
+	var components [][]N
+	counter := 0
+
+	var strongConnect func(u int)
+	strongConnect = func(u int) {
+		index[u], lowlink[u] = counter, counter
+		counter++
+		stack.Push(u)
+		onStack[u] = true
+		for _, e := range g.adj[u] {
+			v := g.index[e.To]
+			if index[v] == -1 {
+				strongConnect(v)
+				lowlink[u] = min(lowlink[u], lowlink[v])
+			} else if onStack[v] {
+				lowlink[u] = min(lowlink[u], index[v])
+			}
+		}
+		if lowlink[u] == index[u] {
+			var component []N
+			for {
+				v, _ := stack.Pop()
+				onStack[v] = false
+				component = append(component, g.nodes[v])
+				if v == u {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+	for u := 0; u < n; u++ {
+		if index[u] == -1 {
+			strongConnect(u)
+		}
+	}
+	return components
+}
+
+// MinimumSpanningTree returns the edges of a minimum spanning tree of an
+// undirected graph and their total weight (Kruskal's algorithm).
+// Returns ErrNotConnected if the graph has more than one component.
+func (g *Graph[N]) MinimumSpanningTree() ([]Edge[N], int, error) {
+	if g.directed {
+		return nil, 0, ErrDirected
+	}
+	edges := g.Edges()
+	sort.SliceStable(edges, func(i, j int) bool { return edges[i].Weight < edges[j].Weight })
+
+	ds := NewDisjointSet(len(g.nodes))
+	var tree []Edge[N]
+	total := 0
+	for _, e := range edges {
+		if ds.Union(g.index[e.From], g.index[e.To]) {
+			tree = append(tree, e)
+			total += e.Weight
+		}
+	}
+	if len(g.nodes) > 0 && len(tree) != len(g.nodes)-1 {
+		return nil, 0, ErrNotConnected
+	}
+	return tree, total, nil
+}
+
+// Validate checks that nodes, the index and the adjacency lists agree,
+// that every edge starts at the node whose list holds it and ends at a
+// known node, and that in an undirected graph every edge between two
+// different nodes is stored once from each end.
+func (g *Graph[N]) Validate() error {
+	if len(g.index) != len(g.nodes) || len(g.adj) != len(g.nodes) {
+		return fmt.Errorf("%w: graph has %d nodes, %d indexed and %d adjacency lists", ErrInvariant, len(g.nodes), len(g.index), len(g.adj))
+	}
+	for i, n := range g.nodes {
+		if j, ok := g.index[n]; !ok || j != i {
+			return fmt.Errorf("%w: graph node %v is at %d but indexed at %d", ErrInvariant, n, i, j)
+		}
+	}
+	// For an undirected graph, count each edge between two different
+	// nodes up from one end and down from the other.
+	type pair struct{ lo, hi, weight int }
+	balance := make(map[pair]int)
+	for i, list := range g.adj {
+		for _, e := range list {
+			j, ok := g.index[e.To]
+			if e.From != g.nodes[i] || !ok {
+				return fmt.Errorf("%w: graph edge %v -> %v is stored at node %v", ErrInvariant, e.From, e.To, g.nodes[i])
+			}
+			if g.directed || i == j {
+				continue
+			}
+			if i < j {
+				balance[pair{i, j, e.Weight}]++
+			} else {
+				balance[pair{j, i, e.Weight}]--
+			}
+		}
+	}
+	for p, n := range balance {
+		if n != 0 {
+			return fmt.Errorf("%w: undirected graph edge %v -- %v (weight %d) is not stored from both ends", ErrInvariant, g.nodes[p.lo], g.nodes[p.hi], p.weight)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,366 @@
+package datastructures
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// ======================================================
+// Interval Tree (Augmented AVL Tree)
+// ======================================================
+
+// Interval is the half-open range [Start, End).
+type Interval[T cmp.Ordered] struct {
+	Start T
+	End   T
+}
+
+// Overlaps reports whether the two intervals share at least one point.
+// Intervals that only touch, like [1, 3) and [3, 5), do not overlap.
+func (iv Interval[T]) Overlaps(other Interval[T]) bool {
+	return iv.Start < other.End && other.Start < iv.End
+}
+
+// Contains reports whether Start <= point < End.
+func (iv Interval[T]) Contains(point T) bool {
+	return iv.Start <= point && point < iv.End
+}
+
+// String formats the interval as "[Start, End)".
+func (iv Interval[T]) String() string {
+	return fmt.Sprintf("[%v, %v)", iv.Start, iv.End)
+}
+
+// IntervalEntry is an interval stored in an IntervalTree with its payload.
+type IntervalEntry[T cmp.Ordered, V any] struct {
+	Interval[T]
+	Value V
+}
+
+// intervalNode is ordered by (Start, End, seq); seq is an insertion
+// counter that keeps identical intervals distinct and in insertion order.
+// maxEnd is the largest End in the subtree and lets queries skip
+// subtrees that end before the query starts.
+type intervalNode[T cmp.Ordered, V any] struct {
+	entry  IntervalEntry[T, V]
+	seq    uint64
+	left   *intervalNode[T, V]
+	right  *intervalNode[T, V]
+	height int
+	maxEnd T
+}
+
+// IntervalTree stores half-open intervals with payloads and finds every
+// interval overlapping a point or a range in O(log n + k) for k results.
+// It is an AVL tree ordered by interval start, augmented with the
+// maximum end of each subtree. Identical intervals may be stored more
+// than once. The zero value is an empty tree ready to use.
+type IntervalTree[T cmp.Ordered, V any] struct {
+	root    *intervalNode[T, V]
+	length  int
+	nextSeq uint64
+}
+
+func (n *intervalNode[T, V]) compare(start, end T, seq uint64) int {
+	if c := cmp.Compare(start, n.entry.Start); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(end, n.entry.End); c != 0 {
+		return c
+	}
+	return cmp.Compare(seq, n.seq)
+}
+
+func intervalHeight[T cmp.Ordered, V any](n *intervalNode[T, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *intervalNode[T, V]) update() {
+	n.height = 1 + max(intervalHeight(n.left), intervalHeight(n.right))
+	n.maxEnd = n.entry.End
+	if n.left != nil {
+		n.maxEnd = max(n.maxEnd, n.left.maxEnd)
+	}
+	if n.right != nil {
+		n.maxEnd = max(n.maxEnd, n.right.maxEnd)
+	}
+}
+
+func (n *intervalNode[T, V]) balanceFactor() int {
+	return intervalHeight(n.left) - intervalHeight(n.right)
+}
+
+func (n *intervalNode[T, V]) rotateRight() *intervalNode[T, V] {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.update()
+	l.update()
+	return l
+}
+
+func (n *intervalNode[T, V]) rotateLeft() *intervalNode[T, V] {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.update()
+	r.update()
+	return r
+}
+
+// rebalance restores the AVL property and maxEnd at n after one of its
+// subtrees changed.
+func (n *intervalNode[T, V]) rebalance() *intervalNode[T, V] {
+	n.update()
+	switch bf := n.balanceFactor(); {
+	case bf > 1:
+		if n.left.balanceFactor() < 0 {
+			n.left = n.left.rotateLeft()
+		}
+		return n.rotateRight()
+	case bf < -1:
+		if n.right.balanceFactor() > 0 {
+			n.right = n.right.rotateRight()
+		}
+		return n.rotateLeft()
+	}
+	return n
+}
+
+// Len returns the number of intervals in the tree.
+func (t *IntervalTree[T, V]) Len() int {
+	return t.length
+}
+
+// Insert adds the interval [start, end) with value. It panics if the
+// interval is empty, that is if start >= end.
+func (t *IntervalTree[T, V]) Insert(start, end T, value V) {
+	if !(start < end) {
+		panic(fmt.Sprintf("datastructures: interval [%v, %v) is empty", start, end))
+	}
+	n := &intervalNode[T, V]{
+		entry: IntervalEntry[T, V]{Interval: Interval[T]{Start: start, End: end}, Value: value},
+		seq:   t.nextSeq,
+	}
+	t.nextSeq++
+	t.root = insertInterval(t.root, n)
+	t.length++
+}
+
+func insertInterval[T cmp.Ordered, V any](root, n *intervalNode[T, V]) *intervalNode[T, V] {
+	if root == nil {
+		n.update()
+		return n
+	}
+	if root.compare(n.entry.Start, n.entry.End, n.seq) < 0 {
+		root.left = insertInterval(root.left, n)
+	} else {
+		root.right = insertInterval(root.right, n)
+	}
+	return root.rebalance()
+}
+
+// Delete removes the earliest inserted interval equal to [start, end).
+// Returns true if one was removed.
+func (t *IntervalTree[T, V]) Delete(start, end T) bool {
+	return t.DeleteFunc(start, end, func(V) bool { return true })
+}
+
+// DeleteFunc removes the earliest inserted interval equal to [start, end)
+// whose value satisfies match. Returns true if one was removed.
+func (t *IntervalTree[T, V]) DeleteFunc(start, end T, match func(V) bool) bool {
+	var target *intervalNode[T, V]
+	t.ascendFrom(t.root, start, end, func(n *intervalNode[T, V]) bool {
+		if n.entry.Start != start || n.entry.End != end {
+			return false
+		}
+		if match(n.entry.Value) {
+			target = n
+			return false
+		}
+		return true
+	})
+	if target == nil {
+		return false
+	}
+	t.root = deleteInterval(t.root, target)
+	t.length--
+	return true
+}
+
+// ascendFrom visits, in order, the nodes at or after (start, end, 0)
+// until visit returns false.
+func (t *IntervalTree[T, V]) ascendFrom(n *intervalNode[T, V], start, end T, visit func(*intervalNode[T, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.compare(start, end, 0) <= 0 {
+		if !t.ascendFrom(n.left, start, end, visit) || !visit(n) {
+			return false
+		}
+	}
+	return t.ascendFrom(n.right, start, end, visit)
+}
+
+func deleteInterval[T cmp.Ordered, V any](n, target *intervalNode[T, V]) *intervalNode[T, V] {
+	switch c := n.compare(target.entry.Start, target.entry.End, target.seq); {
+	case c < 0:
+		n.left = deleteInterval(n.left, target)
+	case c > 0:
+		n.right = deleteInterval(n.right, target)
+	default:
+		if n.left == nil {
+			return n.right
+		}
+		if n.right == nil {
+			return n.left
+		}
+		// Replace n with its in-order successor.
+		succ := n.right
+		for succ.left != nil {
+			succ = succ.left
+		}
+		succ.right = deleteInterval(n.right, succ)
+		succ.left = n.left
+		return succ.rebalance()
+	}
+	return n.rebalance()
+}
+
+// QueryPoint returns every interval containing point, ordered by start.
+func (t *IntervalTree[T, V]) QueryPoint(point T) []IntervalEntry[T, V] {
+	var result []IntervalEntry[T, V]
+	queryIntervals(t.root, func(iv Interval[T]) bool { return iv.Contains(point) },
+		point, point, true, func(e IntervalEntry[T, V]) bool {
+			result = append(result, e)
+			return true
+		})
+	return result
+}
+
+// QueryRange returns every interval overlapping [start, end), ordered by
+// start. An empty query range overlaps nothing.
+func (t *IntervalTree[T, V]) QueryRange(start, end T) []IntervalEntry[T, V] {
+	var result []IntervalEntry[T, V]
+	t.rangeOverlaps(start, end, func(e IntervalEntry[T, V]) bool {
+		result = append(result, e)
+		return true
+	})
+	return result
+}
+
+// FirstOverlap returns the overlapping interval with the smallest start,
+// which is enough to detect a conflict. Returns false if none overlaps.
+func (t *IntervalTree[T, V]) FirstOverlap(start, end T) (IntervalEntry[T, V], bool) {
+	var found IntervalEntry[T, V]
+	ok := false
+	t.rangeOverlaps(start, end, func(e IntervalEntry[T, V]) bool {
+		found, ok = e, true
+		return false
+	})
+	return found, ok
+}
+
+func (t *IntervalTree[T, V]) rangeOverlaps(start, end T, fn func(IntervalEntry[T, V]) bool) {
+	if !(start < end) {
+		return
+	}
+	q := Interval[T]{Start: start, End: end}
+	queryIntervals(t.root, q.Overlaps, start, end, false, fn)
+}
+
+// queryIntervals visits, in order, the entries of n's subtree that
+// satisfy match, pruning subtrees that end at or before lo and right
+// subtrees that start at or after hi (or after hi when hiInclusive).
+func queryIntervals[T cmp.Ordered, V any](n *intervalNode[T, V], match func(Interval[T]) bool,
+	lo, hi T, hiInclusive bool, fn func(IntervalEntry[T, V]) bool) bool {
+	if n == nil || n.maxEnd <= lo {
+		return true
+	}
+	if !queryIntervals(n.left, match, lo, hi, hiInclusive, fn) {
+		return false
+	}
+	if n.entry.Start > hi || (!hiInclusive && n.entry.Start == hi) {
+		return true // This node and everything to its right start too late
+	}
+	if match(n.entry.Interval) && !fn(n.entry) {
+		return false
+	}
+	return queryIntervals(n.right, match, lo, hi, hiInclusive, fn)
+}
+
+// Ascend calls fn for every interval ordered by start, then end, then
+// insertion order, until fn returns false.
+func (t *IntervalTree[T, V]) Ascend(fn func(entry IntervalEntry[T, V]) bool) {
+	var walk func(n *intervalNode[T, V]) bool
+	walk = func(n *intervalNode[T, V]) bool {
+		return n == nil || (walk(n.left) && fn(n.entry) && walk(n.right))
+	}
+	walk(t.root)
+}
+
+// Coverage returns the union of all intervals as a sorted list of
+// disjoint intervals. Intervals that overlap or touch are merged.
+func (t *IntervalTree[T, V]) Coverage() []Interval[T] {
+	var merged []Interval[T]
+	t.Ascend(func(e IntervalEntry[T, V]) bool {
+		if last := len(merged) - 1; last >= 0 && e.Start <= merged[last].End {
+			merged[last].End = max(merged[last].End, e.End)
+		} else {
+			merged = append(merged, e.Interval)
+		}
+		return true
+	})
+	return merged
+}
+
+// Validate checks that intervals are non-empty and in order, and that
+// every node's height, maxEnd and balance factor are correct.
+func (t *IntervalTree[T, V]) Validate() error {
+	count := 0
+	var prev *intervalNode[T, V]
+	var check func(n *intervalNode[T, V]) error
+	check = func(n *intervalNode[T, V]) error {
+		if n == nil {
+			return nil
+		}
+		if err := check(n.left); err != nil {
+			return err
+		}
+		if !(n.entry.Start < n.entry.End) {
+			return fmt.Errorf("%w: interval tree holds empty interval %v", ErrInvariant, n.entry.Interval)
+		}
+		if prev != nil && prev.compare(n.entry.Start, n.entry.End, n.seq) <= 0 {
+			return fmt.Errorf("%w: interval tree holds %v after %v", ErrInvariant, n.entry.Interval, prev.entry.Interval)
+		}
+		if n.seq >= t.nextSeq {
+			return fmt.Errorf("%w: interval %v has sequence %d, not below %d", ErrInvariant, n.entry.Interval, n.seq, t.nextSeq)
+		}
+		prev = n
+		count++
+		if err := check(n.right); err != nil {
+			return err
+		}
+		want := *n
+		want.update()
+		switch {
+		case n.height != want.height:
+			return fmt.Errorf("%w: interval %v has height %d, want %d", ErrInvariant, n.entry.Interval, n.height, want.height)
+		case n.maxEnd != want.maxEnd:
+			return fmt.Errorf("%w: interval %v has maxEnd %v, want %v", ErrInvariant, n.entry.Interval, n.maxEnd, want.maxEnd)
+		case n.balanceFactor() < -1 || n.balanceFactor() > 1:
+			return fmt.Errorf("%w: interval %v has balance factor %d", ErrInvariant, n.entry.Interval, n.balanceFactor())
+		}
+		return nil
+	}
+	if err := check(t.root); err != nil {
+		return err
+	}
+	if count != t.length {
+		return fmt.Errorf("%w: interval tree has %d nodes but length %d", ErrInvariant, count, t.length)
+	}
+	return nil
+}
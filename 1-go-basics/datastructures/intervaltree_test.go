@@ -0,0 +1,123 @@
+package datastructures
+
+import (
+	"slices"
+	"testing"
+)
+
+// bookings returns a tree holding the intervals of a small calendar,
+// each valued by its name.
+func bookings() *IntervalTree[int, string] {
+	var t IntervalTree[int, string]
+	t.Insert(9, 10, "standup")
+	t.Insert(10, 12, "review")
+	t.Insert(11, 13, "lunch")
+	t.Insert(14, 15, "call")
+	t.Insert(14, 15, "call again")
+	t.Insert(20, 22, "late")
+	return &t
+}
+
+func values(entries []IntervalEntry[int, string]) []string {
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Value
+	}
+	return names
+}
+
+func TestIntervalTreeQueryPoint(t *testing.T) {
+	tree := bookings()
+	tests := []struct {
+		point int
+		want  []string
+	}{
+		{8, []string{}},
+		{9, []string{"standup"}},
+		{10, []string{"review"}}, // standup ends at 10, exclusive
+		{11, []string{"review", "lunch"}},
+		{12, []string{"lunch"}},
+		{14, []string{"call", "call again"}},
+		{15, []string{}},
+		{21, []string{"late"}},
+		{22, []string{}},
+	}
+	for _, tt := range tests {
+		if got := values(tree.QueryPoint(tt.point)); !slices.Equal(got, tt.want) {
+			t.Errorf("QueryPoint(%d) = %v, want %v", tt.point, got, tt.want)
+		}
+	}
+}
+
+func TestIntervalTreeQueryRange(t *testing.T) {
+	tree := bookings()
+	tests := []struct {
+		start, end int
+		want       []string
+	}{
+		{0, 9, []string{}},
+		{0, 10, []string{"standup"}},
+		{10, 11, []string{"review"}},
+		{12, 14, []string{"lunch"}},
+		{13, 14, []string{}},
+		{9, 23, []string{"standup", "review", "lunch", "call", "call again", "late"}},
+		{11, 11, []string{}}, // Empty query range
+		{15, 11, []string{}}, // Reversed query range
+	}
+	for _, tt := range tests {
+		if got := values(tree.QueryRange(tt.start, tt.end)); !slices.Equal(got, tt.want) {
+			t.Errorf("QueryRange(%d, %d) = %v, want %v", tt.start, tt.end, got, tt.want)
+		}
+		first, ok := tree.FirstOverlap(tt.start, tt.end)
+		if ok != (len(tt.want) > 0) || (ok && first.Value != tt.want[0]) {
+			t.Errorf("FirstOverlap(%d, %d) = %v, %v, want %v", tt.start, tt.end, first.Value, ok, tt.want)
+		}
+	}
+}
+
+func TestIntervalTreeDelete(t *testing.T) {
+	tree := bookings()
+	if tree.Delete(14, 16) {
+		t.Fatal("Delete of an absent interval returned true")
+	}
+	if !tree.Delete(14, 15) {
+		t.Fatal("Delete(14, 15) returned false")
+	}
+	if got := values(tree.QueryPoint(14)); !slices.Equal(got, []string{"call again"}) {
+		t.Fatalf("after Delete, QueryPoint(14) = %v, want the later duplicate", got)
+	}
+	if !tree.DeleteFunc(10, 12, func(v string) bool { return v == "review" }) {
+		t.Fatal("DeleteFunc(10, 12, review) returned false")
+	}
+	if tree.DeleteFunc(11, 13, func(v string) bool { return v == "review" }) {
+		t.Fatal("DeleteFunc matched the wrong value")
+	}
+	if tree.Len() != 4 {
+		t.Fatalf("Len = %d, want 4", tree.Len())
+	}
+	if err := tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIntervalTreeCoverage(t *testing.T) {
+	tree := bookings()
+	want := []Interval[int]{{9, 13}, {14, 15}, {20, 22}}
+	if got := tree.Coverage(); !slices.Equal(got, want) {
+		t.Fatalf("Coverage = %v, want %v", got, want)
+	}
+	var empty IntervalTree[int, string]
+	if got := empty.Coverage(); len(got) != 0 {
+		t.Fatalf("Coverage of an empty tree = %v", got)
+	}
+}
+
+func TestIntervalTreeInsertEmptyPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Insert(5, 5) did not panic")
+		}
+	}()
+	var tree IntervalTree[int, string]
+	tree.Insert(5, 5, "empty")
+}
@@ -0,0 +1,385 @@
+package datastructures
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"math/bits"
+	"reflect"
+)
+
+// ======================================================
+// Persistent Map (Hash Array Mapped Trie)
+// ======================================================
+
+const (
+	pmapBits = 5
+	pmapMask = 1<<pmapBits - 1
+)
+
+type pmapEntry[K comparable, V any] struct {
+	hash  uint64
+	key   K
+	value V
+}
+
+// pmapSlot holds either an entry or, when child is non-nil, a subtree.
+type pmapSlot[K comparable, V any] struct {
+	child *pmapNode[K, V]
+	entry pmapEntry[K, V]
+}
+
+// pmapNode is a trie node. Each level consumes 5 bits of the key hash;
+// bitmap marks which of the 32 possible slots are present, and slots
+// holds only those, in order. Keys whose 64-bit hashes are identical end
+// up in a collision node, which stores them in a plain list instead.
+type pmapNode[K comparable, V any] struct {
+	bitmap     uint32
+	slots      []pmapSlot[K, V]
+	collisions []pmapEntry[K, V]
+}
+
+// PMap is an immutable hash map. Set and Delete return a new version
+// that shares all but O(log32 n) nodes with the old one, and every
+// version stays valid. Since no version is ever modified, any number of
+// goroutines may read any version without locking, and taking a
+// snapshot is just keeping a pointer.
+type PMap[K comparable, V any] struct {
+	root   *pmapNode[K, V]
+	length int
+	hash   func(K) uint64
+}
+
+// NewPMap returns an empty PMap for keys of a built-in ordered type or a
+// type defined on one.
+func NewPMap[K cmp.Ordered, V any]() *PMap[K, V] {
+	seed := maphash.MakeSeed()
+	return &PMap[K, V]{hash: func(k K) uint64 { return hashOrdered(seed, k) }}
+}
+
+// NewPMapFunc returns an empty PMap that hashes keys with hash. Keys
+// that are equal must have equal hashes.
+func NewPMapFunc[K comparable, V any](hash func(K) uint64) *PMap[K, V] {
+	return &PMap[K, V]{hash: hash}
+}
+
+// hashOrdered hashes any cmp.Ordered value.
+func hashOrdered[K cmp.Ordered](seed maphash.Seed, k K) uint64 {
+	switch k := any(k).(type) {
+	case string:
+		return maphash.String(seed, k)
+	case int:
+		return hashUint64(seed, uint64(k))
+	}
+	// Other integer and float kinds, and named types, go through reflect.
+	v := reflect.ValueOf(k)
+	switch v.Kind() {
+	case reflect.String:
+		return maphash.String(seed, v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return hashUint64(seed, uint64(v.Int()))
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f == 0 {
+			f = 0 // -0 == +0, so they must hash alike
+		}
+		return hashUint64(seed, math.Float64bits(f))
+	default:
+		return hashUint64(seed, v.Uint())
+	}
+}
+
+func hashUint64(seed maphash.Seed, x uint64) uint64 {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], x)
+	return maphash.Bytes(seed, buf[:])
+}
+
+// Len returns the number of entries.
+func (m *PMap[K, V]) Len() int {
+	return m.length
+}
+
+// Get returns the value stored under key. Returns false if absent.
+func (m *PMap[K, V]) Get(key K) (V, bool) {
+	h := m.hash(key)
+	node := m.root
+	for shift := uint(0); node != nil; shift += pmapBits {
+		if node.collisions != nil {
+			for _, e := range node.collisions {
+				if e.key == key {
+					return e.value, true
+				}
+			}
+			break
+		}
+		bit := uint32(1) << ((h >> shift) & pmapMask)
+		if node.bitmap&bit == 0 {
+			break
+		}
+		slot := node.slots[bits.OnesCount32(node.bitmap&(bit-1))]
+		if slot.child == nil {
+			if slot.entry.key == key {
+				return slot.entry.value, true
+			}
+			break
+		}
+		node = slot.child
+	}
+	var zero V
+	return zero, false
+}
+
+// Contains reports whether key is present.
+func (m *PMap[K, V]) Contains(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Set returns a new version with key mapped to value.
+func (m *PMap[K, V]) Set(key K, value V) *PMap[K, V] {
+	e := pmapEntry[K, V]{hash: m.hash(key), key: key, value: value}
+	root, added := setPMap(m.root, 0, e)
+	length := m.length
+	if added {
+		length++
+	}
+	return &PMap[K, V]{root: root, length: length, hash: m.hash}
+}
+
+func setPMap[K comparable, V any](node *pmapNode[K, V], shift uint, e pmapEntry[K, V]) (*pmapNode[K, V], bool) {
+	if node == nil {
+		return newPMapNode(shift, e), true
+	}
+	if node.collisions != nil {
+		collisions := append([]pmapEntry[K, V](nil), node.collisions...)
+		for i, c := range collisions {
+			if c.key == e.key {
+				collisions[i] = e
+				return &pmapNode[K, V]{collisions: collisions}, false
+			}
+		}
+		return &pmapNode[K, V]{collisions: append(collisions, e)}, true
+	}
+
+	bit := uint32(1) << ((e.hash >> shift) & pmapMask)
+	pos := bits.OnesCount32(node.bitmap & (bit - 1))
+	if node.bitmap&bit == 0 {
+		slots := make([]pmapSlot[K, V], 0, len(node.slots)+1)
+		slots = append(append(append(slots, node.slots[:pos]...), pmapSlot[K, V]{entry: e}), node.slots[pos:]...)
+		return &pmapNode[K, V]{bitmap: node.bitmap | bit, slots: slots}, true
+	}
+
+	slots := append([]pmapSlot[K, V](nil), node.slots...)
+	slot := slots[pos]
+	added := true
+	switch {
+	case slot.child != nil:
+		slots[pos].child, added = setPMap(slot.child, shift+pmapBits, e)
+	case slot.entry.key == e.key:
+		slots[pos].entry, added = e, false
+	default:
+		// Two keys share this slot; push both one level down.
+		child := newPMapNode(shift+pmapBits, slot.entry)
+		slots[pos] = pmapSlot[K, V]{}
+		slots[pos].child, _ = setPMap(child, shift+pmapBits, e)
+	}
+	return &pmapNode[K, V]{bitmap: node.bitmap, slots: slots}, added
+}
+
+// newPMapNode returns a node holding only e, or a collision node once
+// the hash bits are used up.
+func newPMapNode[K comparable, V any](shift uint, e pmapEntry[K, V]) *pmapNode[K, V] {
+	if shift >= 64 {
+		return &pmapNode[K, V]{collisions: []pmapEntry[K, V]{e}}
+	}
+	bit := uint32(1) << ((e.hash >> shift) & pmapMask)
+	return &pmapNode[K, V]{bitmap: bit, slots: []pmapSlot[K, V]{{entry: e}}}
+}
+
+// Delete returns a new version without key. If key is absent it returns m.
+func (m *PMap[K, V]) Delete(key K) *PMap[K, V] {
+	root, removed := deletePMap(m.root, 0, m.hash(key), key)
+	if !removed {
+		return m
+	}
+	return &PMap[K, V]{root: root, length: m.length - 1, hash: m.hash}
+}
+
+// deletePMap returns a copy of node without key, or nil if it becomes
+// empty. A subtree left with a single entry is replaced by that entry,
+// so the trie never keeps chains of one-entry nodes.
+func deletePMap[K comparable, V any](node *pmapNode[K, V], shift uint, h uint64, key K) (*pmapNode[K, V], bool) {
+	if node == nil {
+		return nil, false
+	}
+	if node.collisions != nil {
+		for i, c := range node.collisions {
+			if c.key == key {
+				if len(node.collisions) == 1 {
+					return nil, true
+				}
+				collisions := append(append([]pmapEntry[K, V](nil), node.collisions[:i]...), node.collisions[i+1:]...)
+				return &pmapNode[K, V]{collisions: collisions}, true
+			}
+		}
+		return node, false
+	}
+
+	bit := uint32(1) << ((h >> shift) & pmapMask)
+	if node.bitmap&bit == 0 {
+		return node, false
+	}
+	pos := bits.OnesCount32(node.bitmap & (bit - 1))
+	slot := node.slots[pos]
+	var replacement *pmapSlot[K, V]
+	if slot.child != nil {
+		child, removed := deletePMap(slot.child, shift+pmapBits, h, key)
+		if !removed {
+			return node, false
+		}
+		if child != nil {
+			replacement = &pmapSlot[K, V]{child: child}
+			if e, ok := child.single(); ok {
+				replacement = &pmapSlot[K, V]{entry: e}
+			}
+		}
+	} else if slot.entry.key != key {
+		return node, false
+	}
+
+	if replacement != nil {
+		slots := append([]pmapSlot[K, V](nil), node.slots...)
+		slots[pos] = *replacement
+		return &pmapNode[K, V]{bitmap: node.bitmap, slots: slots}, true
+	}
+	if len(node.slots) == 1 {
+		return nil, true
+	}
+	slots := append(append([]pmapSlot[K, V](nil), node.slots[:pos]...), node.slots[pos+1:]...)
+	return &pmapNode[K, V]{bitmap: node.bitmap &^ bit, slots: slots}, true
+}
+
+// single returns the only entry of node if it holds exactly one entry
+// and no subtrees.
+func (n *pmapNode[K, V]) single() (pmapEntry[K, V], bool) {
+	if len(n.collisions) == 1 {
+		return n.collisions[0], true
+	}
+	if len(n.slots) == 1 && n.slots[0].child == nil {
+		return n.slots[0].entry, true
+	}
+	return pmapEntry[K, V]{}, false
+}
+
+// Range calls fn for each entry, in no particular order, until fn
+// returns false.
+func (m *PMap[K, V]) Range(fn func(key K, value V) bool) {
+	rangePMap(m.root, fn)
+}
+
+func rangePMap[K comparable, V any](node *pmapNode[K, V], fn func(K, V) bool) bool {
+	if node == nil {
+		return true
+	}
+	for _, e := range node.collisions {
+		if !fn(e.key, e.value) {
+			return false
+		}
+	}
+	for _, slot := range node.slots {
+		if slot.child != nil {
+			if !rangePMap(slot.child, fn) {
+				return false
+			}
+		} else if !fn(slot.entry.key, slot.entry.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks that every entry's stored hash matches its key and
+// sits on the path its hash selects, that bitmaps match their slots, that
+// collision nodes only appear once the hash bits are used up, and that
+// every subtree below the root holds at least two entries, as Delete
+// collapses smaller ones.
+func (m *PMap[K, V]) Validate() error {
+	if m.root == nil {
+		if m.length != 0 {
+			return fmt.Errorf("%w: pmap is empty but has length %d", ErrInvariant, m.length)
+		}
+		return nil
+	}
+	entries, err := m.validateNode(m.root, 0, 0)
+	if err != nil {
+		return err
+	}
+	if entries != m.length {
+		return fmt.Errorf("%w: pmap holds %d entries but length %d", ErrInvariant, entries, m.length)
+	}
+	return nil
+}
+
+// validateNode checks node, reached by the hash bits path below shift,
+// and returns the number of entries in its subtree.
+func (m *PMap[K, V]) validateNode(node *pmapNode[K, V], shift uint, path uint64) (int, error) {
+	prefix := uint64(1)<<shift - 1 // all ones once shift reaches 64
+	checkEntry := func(e pmapEntry[K, V]) error {
+		if h := m.hash(e.key); e.hash != h {
+			return fmt.Errorf("%w: pmap key %v has stored hash %#x, want %#x", ErrInvariant, e.key, e.hash, h)
+		}
+		if e.hash&prefix != path {
+			return fmt.Errorf("%w: pmap key %v is on the wrong path", ErrInvariant, e.key)
+		}
+		return nil
+	}
+
+	if node.collisions != nil {
+		if shift < 64 || len(node.collisions) < 2 || node.bitmap != 0 || node.slots != nil {
+			return 0, fmt.Errorf("%w: pmap collision node at shift %d has %d entries", ErrInvariant, shift, len(node.collisions))
+		}
+		for i, e := range node.collisions {
+			if err := checkEntry(e); err != nil {
+				return 0, err
+			}
+			for _, other := range node.collisions[:i] {
+				if other.key == e.key {
+					return 0, fmt.Errorf("%w: pmap key %v appears twice", ErrInvariant, e.key)
+				}
+			}
+		}
+		return len(node.collisions), nil
+	}
+
+	if shift >= 64 || len(node.slots) == 0 || bits.OnesCount32(node.bitmap) != len(node.slots) {
+		return 0, fmt.Errorf("%w: pmap node at shift %d has bitmap %#x and %d slots", ErrInvariant, shift, node.bitmap, len(node.slots))
+	}
+	entries := 0
+	bitmap := node.bitmap
+	for _, slot := range node.slots {
+		index := uint64(bits.TrailingZeros32(bitmap))
+		bitmap &= bitmap - 1
+		if slot.child == nil {
+			if err := checkEntry(slot.entry); err != nil {
+				return 0, err
+			}
+			if slot.entry.hash>>shift&pmapMask != index {
+				return 0, fmt.Errorf("%w: pmap key %v is in slot %d", ErrInvariant, slot.entry.key, index)
+			}
+			entries++
+			continue
+		}
+		n, err := m.validateNode(slot.child, shift+pmapBits, path|index<<shift)
+		if err != nil {
+			return 0, err
+		}
+		if n < 2 {
+			return 0, fmt.Errorf("%w: pmap subtree at shift %d holds a single entry", ErrInvariant, shift+pmapBits)
+		}
+		entries += n
+	}
+	return entries, nil
+}
@@ -0,0 +1,140 @@
+package datastructures
+
+import (
+	"maps"
+	"math/rand"
+	"testing"
+)
+
+// checkPMap fails unless m holds exactly the entries of want.
+func checkPMap[K comparable, V comparable](t *testing.T, m *PMap[K, V], want map[K]V) {
+	t.Helper()
+	if m.Len() != len(want) {
+		t.Fatalf("Len = %d, want %d", m.Len(), len(want))
+	}
+	for k, v := range want {
+		if got, ok := m.Get(k); !ok || got != v {
+			t.Fatalf("Get(%v) = %v, %v, want %v, true", k, got, ok, v)
+		}
+	}
+	seen := make(map[K]V)
+	m.Range(func(k K, v V) bool {
+		if _, dup := seen[k]; dup {
+			t.Fatalf("Range visited %v twice", k)
+		}
+		seen[k] = v
+		return true
+	})
+	if !maps.Equal(seen, want) {
+		t.Fatalf("Range visited %v, want %v", seen, want)
+	}
+}
+
+func TestPMapHashes(t *testing.T) {
+	tests := []struct {
+		name string
+		hash func(int) uint64
+	}{
+		{"default", nil},
+		// Every key in a handful of 5-bit slots, so the trie goes deep.
+		{"shared prefixes", func(k int) uint64 { return uint64(k%4) << 60 }},
+		// Identical 64-bit hashes force collision nodes.
+		{"full collisions", func(k int) uint64 { return uint64(k % 3) }},
+		{"constant", func(int) uint64 { return 7 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewPMap[int, int]()
+			if tt.hash != nil {
+				m = NewPMapFunc[int, int](tt.hash)
+			}
+			rng := rand.New(rand.NewSource(1))
+			model := make(map[int]int)
+			for i := 0; i < 2000; i++ {
+				k := rng.Intn(100)
+				if rng.Intn(3) == 0 {
+					m = m.Delete(k)
+					delete(model, k)
+				} else {
+					m = m.Set(k, i)
+					model[k] = i
+				}
+			}
+			checkPMap(t, m, model)
+			for k := range model {
+				m = m.Delete(k)
+			}
+			checkPMap(t, m, map[int]int{})
+		})
+	}
+}
+
+func TestPMapPersistence(t *testing.T) {
+	collide := func(k string) uint64 { return uint64(len(k)) }
+	for _, m := range []*PMap[string, int]{NewPMap[string, int](), NewPMapFunc[string, int](collide)} {
+		var versions []*PMap[string, int]
+		var models []map[string]int
+		model := make(map[string]int)
+		keys := []string{"a", "b", "c", "aa", "bb", "cc", "abc"}
+		for i, k := range keys {
+			m = m.Set(k, i)
+			model[k] = i
+			versions = append(versions, m)
+			models = append(models, maps.Clone(model))
+		}
+		for i, k := range keys {
+			m = m.Set(k, -i).Delete(keys[len(keys)-1-i])
+		}
+		for i, v := range versions {
+			checkPMap(t, v, models[i])
+		}
+	}
+}
+
+func TestPMapNoOps(t *testing.T) {
+	m := NewPMap[string, int]().Set("a", 1).Set("b", 2)
+	if got := m.Delete("missing"); got != m {
+		t.Fatal("Delete of an absent key returned a new version")
+	}
+	empty := NewPMap[string, int]()
+	if got := empty.Delete("a"); got != empty || got.Len() != 0 {
+		t.Fatal("Delete on an empty map changed it")
+	}
+	if _, ok := empty.Get("a"); ok {
+		t.Fatal("Get on an empty map found a key")
+	}
+}
+
+func TestPVector(t *testing.T) {
+	// Sizes straddle the 32-item leaf and 1024-item two-level boundaries.
+	for _, n := range []int{0, 1, 31, 32, 33, 1024, 1025, 1056, 1057} {
+		v := NewPVector[int]()
+		for i := 0; i < n; i++ {
+			v = v.Append(i)
+		}
+		if v.Len() != n {
+			t.Fatalf("Len = %d, want %d", v.Len(), n)
+		}
+		for i := 0; i < n; i++ {
+			if v.Get(i) != i {
+				t.Fatalf("n=%d: Get(%d) = %d", n, i, v.Get(i))
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		changed := v.Set(n/2, -1)
+		if v.Get(n/2) != n/2 || changed.Get(n/2) != -1 {
+			t.Fatalf("n=%d: Set changed the original version", n)
+		}
+		for popped := v; popped.Len() > 0; {
+			popped = popped.Pop()
+			if s := popped.Slice(); len(s) != popped.Len() || (len(s) > 0 && s[len(s)-1] != len(s)-1) {
+				t.Fatalf("n=%d: Pop left %d items ending in the wrong value", n, len(s))
+			}
+		}
+		if v.Len() != n {
+			t.Fatalf("n=%d: Pop changed the original version", n)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package datastructures
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// ======================================================
+// Generic Priority Queue (Facade over container/heap)
+// ======================================================
+
+// PQHandle refers to an item stored in a PQ. It is returned by Push and
+// passed back to Update and Remove; callers never touch heap indexes.
+type PQHandle[T any] struct {
+	value    T
+	priority int
+	seq      uint64 // insertion order, breaks ties between equal priorities
+	index    int    // position in the heap, -1 once the item has left the queue
+}
+
+// Value returns the value stored in the item.
+func (h *PQHandle[T]) Value() T {
+	return h.value
+}
+
+// Priority returns the current priority of the item.
+func (h *PQHandle[T]) Priority() int {
+	return h.priority
+}
+
+// pqHeap implements heap.Interface for PQ. Items with equal priority are
+// ordered by insertion sequence so they come out first-in, first-out.
+type pqHeap[T any] struct {
+	items []*PQHandle[T]
+	less  func(a, b int) bool
+}
+
+func (h *pqHeap[T]) Len() int { return len(h.items) }
+
+func (h *pqHeap[T]) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.less(a.priority, b.priority) {
+		return true
+	}
+	if h.less(b.priority, a.priority) {
+		return false
+	}
+	return a.seq < b.seq
+}
+
+func (h *pqHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *pqHeap[T]) Push(x interface{}) {
+	item := x.(*PQHandle[T])
+	item.index = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *pqHeap[T]) Pop() interface{} {
+	n := len(h.items)
+	item := h.items[n-1]
+	h.items[n-1] = nil // Avoid memory leak
+	item.index = -1    // For safety
+	h.items = h.items[:n-1]
+	return item
+}
+
+// PQ is a priority queue of values of type T with int priorities.
+// Items with equal priority are returned in the order they were pushed.
+// Create one with NewMinPQ, NewMaxPQ or NewPQ.
+type PQ[T any] struct {
+	heap pqHeap[T]
+	seq  uint64
+}
+
+// NewMinPQ returns a priority queue that pops the lowest priority first,
+// matching the ordering of PriorityQueue.
+func NewMinPQ[T any]() *PQ[T] {
+	return NewPQ[T](func(a, b int) bool { return a < b })
+}
+
+// NewMaxPQ returns a priority queue that pops the highest priority first.
+func NewMaxPQ[T any]() *PQ[T] {
+	return NewPQ[T](func(a, b int) bool { return a > b })
+}
+
+// NewPQ returns a priority queue ordered by a custom comparator.
+// less(a, b) reports whether priority a should be popped before priority b.
+func NewPQ[T any](less func(a, b int) bool) *PQ[T] {
+	return &PQ[T]{heap: pqHeap[T]{less: less}}
+}
+
+// Len returns the number of items in the queue.
+func (pq *PQ[T]) Len() int {
+	return pq.heap.Len()
+}
+
+// IsEmpty returns true if the queue is empty.
+func (pq *PQ[T]) IsEmpty() bool {
+	return pq.heap.Len() == 0
+}
+
+// Push adds value with the given priority and returns a handle to it.
+func (pq *PQ[T]) Push(value T, priority int) *PQHandle[T] {
+	item := &PQHandle[T]{value: value, priority: priority, seq: pq.seq}
+	pq.seq++
+	heap.Push(&pq.heap, item)
+	return item
+}
+
+// Pop removes and returns the value with the highest precedence and its
+// priority. Returns false if the queue is empty.
+func (pq *PQ[T]) Pop() (T, int, bool) {
+	if pq.heap.Len() == 0 {
+		var zero T
+		return zero, 0, false
+	}
+	item := heap.Pop(&pq.heap).(*PQHandle[T])
+	return item.value, item.priority, true
+}
+
+// Peek returns the value that Pop would return without removing it.
+// Returns false if the queue is empty.
+func (pq *PQ[T]) Peek() (T, int, bool) {
+	if pq.heap.Len() == 0 {
+		var zero T
+		return zero, 0, false
+	}
+	item := pq.heap.items[0]
+	return item.value, item.priority, true
+}
+
+// Update changes the priority of the item behind handle.
+// Returns false if the item is no longer in this queue.
+func (pq *PQ[T]) Update(handle *PQHandle[T], priority int) bool {
+	if !pq.owns(handle) {
+		return false
+	}
+	handle.priority = priority
+	heap.Fix(&pq.heap, handle.index)
+	return true
+}
+
+// Remove deletes the item behind handle from the queue.
+// Returns false if the item is no longer in this queue.
+func (pq *PQ[T]) Remove(handle *PQHandle[T]) bool {
+	if !pq.owns(handle) {
+		return false
+	}
+	heap.Remove(&pq.heap, handle.index)
+	return true
+}
+
+// owns reports whether handle currently sits in this queue's heap.
+func (pq *PQ[T]) owns(handle *PQHandle[T]) bool {
+	return handle != nil && handle.index >= 0 && handle.index < len(pq.heap.items) &&
+		pq.heap.items[handle.index] == handle
+}
+
+// Validate checks the heap order and that every handle's index matches
+// its position.
+func (pq *PQ[T]) Validate() error {
+	for i, item := range pq.heap.items {
+		if item.index != i {
+			return fmt.Errorf("%w: pq item %d has index %d", ErrInvariant, i, item.index)
+		}
+		if i > 0 && pq.heap.Less(i, (i-1)/2) {
+			return fmt.Errorf("%w: pq item %d is ordered before its parent", ErrInvariant, i)
+		}
+		if item.seq >= pq.seq {
+			return fmt.Errorf("%w: pq item %d has sequence %d, not below %d", ErrInvariant, i, item.seq, pq.seq)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package datastructures
+
+import "fmt"
+
+// ======================================================
+// Persistent Vector (Bit-Partitioned Trie)
+// ======================================================
+
+const (
+	pvBits  = 5
+	pvWidth = 1 << pvBits
+	pvMask  = pvWidth - 1
+)
+
+// pvNode is a trie node. Branches use children and leaves use values;
+// nodes are never modified once they are reachable from a PVector.
+type pvNode[T any] struct {
+	children []*pvNode[T]
+	values   []T
+}
+
+// PVector is an immutable indexed sequence. Append, Set and Pop return a
+// new version that shares all but O(log32 n) nodes with the old one, and
+// every version stays valid. Since no version is ever modified, any
+// number of goroutines may read any version without locking.
+//
+// Elements live in a 32-way trie, plus a tail of up to 32 elements that
+// makes Append amortized O(1). Get and Set are O(log32 n), which is at
+// most 7 levels for any int index.
+type PVector[T any] struct {
+	count int
+	shift uint // bits consumed by the root level
+	root  *pvNode[T]
+	tail  []T
+}
+
+// NewPVector returns a PVector holding items.
+func NewPVector[T any](items ...T) *PVector[T] {
+	v := &PVector[T]{}
+	for _, item := range items {
+		v = v.Append(item)
+	}
+	return v
+}
+
+// Len returns the number of elements.
+func (v *PVector[T]) Len() int {
+	return v.count
+}
+
+// tailOffset returns the index of the first element stored in the tail.
+func (v *PVector[T]) tailOffset() int {
+	return v.count - len(v.tail)
+}
+
+func (v *PVector[T]) checkIndex(i int) {
+	if i < 0 || i >= v.count {
+		panic(fmt.Sprintf("datastructures: pvector index %d out of range [0:%d]", i, v.count))
+	}
+}
+
+// leafFor returns the values of the leaf holding element i.
+func (v *PVector[T]) leafFor(i int) []T {
+	if i >= v.tailOffset() {
+		return v.tail
+	}
+	node := v.root
+	for level := v.shift; level > 0; level -= pvBits {
+		node = node.children[(i>>level)&pvMask]
+	}
+	return node.values
+}
+
+// Get returns element i. It panics if i is out of range.
+func (v *PVector[T]) Get(i int) T {
+	v.checkIndex(i)
+	return v.leafFor(i)[i&pvMask]
+}
+
+// Append returns a new version with item added at the end.
+func (v *PVector[T]) Append(item T) *PVector[T] {
+	if len(v.tail) < pvWidth {
+		tail := make([]T, len(v.tail)+1, pvWidth)
+		copy(tail, v.tail)
+		tail[len(v.tail)] = item
+		return &PVector[T]{count: v.count + 1, shift: v.shift, root: v.root, tail: tail}
+	}
+
+	// The tail is full: push it into the trie as a leaf and start a new one.
+	leaf := &pvNode[T]{values: v.tail}
+	root, shift := v.root, v.shift
+	switch {
+	case root == nil:
+		root, shift = &pvNode[T]{children: []*pvNode[T]{leaf}}, pvBits
+	case v.count>>pvBits > 1<<shift:
+		// The trie is full at this height; grow a new root above it.
+		root = &pvNode[T]{children: []*pvNode[T]{root, newPVPath(shift, leaf)}}
+		shift += pvBits
+	default:
+		root = v.pushTail(shift, root, leaf)
+	}
+	tail := make([]T, 1, pvWidth)
+	tail[0] = item
+	return &PVector[T]{count: v.count + 1, shift: shift, root: root, tail: tail}
+}
+
+// newPVPath wraps leaf in branches until it sits level bits deep.
+func newPVPath[T any](level uint, leaf *pvNode[T]) *pvNode[T] {
+	if level == 0 {
+		return leaf
+	}
+	return &pvNode[T]{children: []*pvNode[T]{newPVPath(level-pvBits, leaf)}}
+}
+
+// pushTail returns a copy of node with leaf added as the last element.
+func (v *PVector[T]) pushTail(level uint, node, leaf *pvNode[T]) *pvNode[T] {
+	sub := ((v.count - 1) >> level) & pvMask
+	children := append(make([]*pvNode[T], 0, sub+1), node.children...)
+	var child *pvNode[T]
+	switch {
+	case level == pvBits:
+		child = leaf
+	case sub < len(node.children):
+		child = v.pushTail(level-pvBits, node.children[sub], leaf)
+	default:
+		child = newPVPath(level-pvBits, leaf)
+	}
+	if sub < len(children) {
+		children[sub] = child
+	} else {
+		children = append(children, child)
+	}
+	return &pvNode[T]{children: children}
+}
+
+// Set returns a new version with element i replaced by item. It panics
+// if i is out of range.
+func (v *PVector[T]) Set(i int, item T) *PVector[T] {
+	v.checkIndex(i)
+	if i >= v.tailOffset() {
+		tail := append(make([]T, 0, pvWidth), v.tail...)
+		tail[i&pvMask] = item
+		return &PVector[T]{count: v.count, shift: v.shift, root: v.root, tail: tail}
+	}
+	return &PVector[T]{count: v.count, shift: v.shift, root: setPV(v.shift, v.root, i, item), tail: v.tail}
+}
+
+func setPV[T any](level uint, node *pvNode[T], i int, item T) *pvNode[T] {
+	if level == 0 {
+		values := append([]T(nil), node.values...)
+		values[i&pvMask] = item
+		return &pvNode[T]{values: values}
+	}
+	children := append([]*pvNode[T](nil), node.children...)
+	sub := (i >> level) & pvMask
+	children[sub] = setPV(level-pvBits, children[sub], i, item)
+	return &pvNode[T]{children: children}
+}
+
+// Pop returns a new version without the last element. It panics if the
+// vector is empty.
+func (v *PVector[T]) Pop() *PVector[T] {
+	switch {
+	case v.count == 0:
+		panic("datastructures: Pop on empty PVector")
+	case v.count == 1:
+		return &PVector[T]{}
+	case len(v.tail) > 1:
+		n := len(v.tail) - 1
+		return &PVector[T]{count: v.count - 1, shift: v.shift, root: v.root, tail: v.tail[:n:n]}
+	}
+
+	// The tail becomes empty: the last leaf of the trie becomes the tail.
+	tail := v.leafFor(v.count - 2)
+	root, shift := v.popTail(v.shift, v.root), v.shift
+	switch {
+	case root == nil:
+		shift = 0
+	case shift > pvBits && len(root.children) == 1:
+		root, shift = root.children[0], shift-pvBits
+	}
+	return &PVector[T]{count: v.count - 1, shift: shift, root: root, tail: tail}
+}
+
+// popTail returns a copy of node without its last leaf, or nil if that
+// leaves it empty.
+func (v *PVector[T]) popTail(level uint, node *pvNode[T]) *pvNode[T] {
+	sub := ((v.count - 2) >> level) & pvMask
+	if level > pvBits {
+		child := v.popTail(level-pvBits, node.children[sub])
+		if child == nil && sub == 0 {
+			return nil
+		}
+		children := append([]*pvNode[T](nil), node.children[:sub]...)
+		if child != nil {
+			children = append(children, child)
+		}
+		return &pvNode[T]{children: children}
+	}
+	if sub == 0 {
+		return nil
+	}
+	return &pvNode[T]{children: node.children[:sub:sub]}
+}
+
+// Range calls fn for each element in index order until fn returns false.
+func (v *PVector[T]) Range(fn func(i int, item T) bool) {
+	for i := 0; i < v.count; i += pvWidth {
+		leaf := v.leafFor(i)
+		for j, item := range leaf {
+			if !fn(i+j, item) {
+				return
+			}
+		}
+	}
+}
+
+// Slice returns the elements as a new slice.
+func (v *PVector[T]) Slice() []T {
+	items := make([]T, 0, v.count)
+	v.Range(func(_ int, item T) bool {
+		items = append(items, item)
+		return true
+	})
+	return items
+}
+
+// Validate checks that the tail is non-empty and at most 32 elements,
+// that the trie is exactly as tall as it needs to be, and that it holds
+// full leaves for every index before the tail and no other nodes.
+func (v *PVector[T]) Validate() error {
+	offset := v.tailOffset()
+	switch {
+	case v.count < 0 || len(v.tail) > pvWidth || (v.count > 0) != (len(v.tail) > 0):
+		return fmt.Errorf("%w: pvector has %d elements and a tail of %d", ErrInvariant, v.count, len(v.tail))
+	case offset < 0 || offset&pvMask != 0:
+		return fmt.Errorf("%w: pvector tail starts at %d, not a multiple of %d", ErrInvariant, offset, pvWidth)
+	case v.root == nil:
+		if offset != 0 || v.shift != 0 {
+			return fmt.Errorf("%w: pvector has no trie but a tail offset of %d and shift %d", ErrInvariant, offset, v.shift)
+		}
+		return nil
+	case v.shift < pvBits || v.shift%pvBits != 0 || (v.shift+pvBits < 63 && offset > 1<<(v.shift+pvBits)):
+		return fmt.Errorf("%w: pvector shift %d does not fit %d elements", ErrInvariant, v.shift, offset)
+	case v.shift > pvBits && len(v.root.children) < 2:
+		return fmt.Errorf("%w: pvector root at shift %d has a single child", ErrInvariant, v.shift)
+	}
+	leaves, err := validatePVNode(v.shift, v.root)
+	if err != nil {
+		return err
+	}
+	if leaves != offset/pvWidth {
+		return fmt.Errorf("%w: pvector trie has %d leaves for %d elements", ErrInvariant, leaves, offset)
+	}
+	// With the leaf count right, every index reaching a leaf means the
+	// leaves are packed to the left.
+	for i := 0; i < offset; i += pvWidth {
+		node := v.root
+		for level := v.shift; level > 0; level -= pvBits {
+			sub := (i >> level) & pvMask
+			if sub >= len(node.children) {
+				return fmt.Errorf("%w: pvector index %d has no leaf", ErrInvariant, i)
+			}
+			node = node.children[sub]
+		}
+	}
+	return nil
+}
+
+// validatePVNode checks the shape of the subtree of node at level and
+// returns the number of leaves in it.
+func validatePVNode[T any](level uint, node *pvNode[T]) (int, error) {
+	switch {
+	case node == nil:
+		return 0, fmt.Errorf("%w: pvector has a nil node at level %d", ErrInvariant, level)
+	case level == 0:
+		if len(node.values) != pvWidth || node.children != nil {
+			return 0, fmt.Errorf("%w: pvector leaf has %d values and %d children", ErrInvariant, len(node.values), len(node.children))
+		}
+		return 1, nil
+	case len(node.children) == 0 || len(node.children) > pvWidth || node.values != nil:
+		return 0, fmt.Errorf("%w: pvector branch at level %d has %d children", ErrInvariant, level, len(node.children))
+	}
+	leaves := 0
+	for _, child := range node.children {
+		n, err := validatePVNode(level-pvBits, child)
+		if err != nil {
+			return 0, err
+		}
+		leaves += n
+	}
+	return leaves, nil
+}
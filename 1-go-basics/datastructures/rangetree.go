@@ -0,0 +1,346 @@
+package datastructures
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ======================================================
+// Fenwick Tree (Binary Indexed Tree)
+// ======================================================
+
+// FenwickTree maintains prefix sums over a fixed-size array of numbers.
+// Point updates and range-sum queries are O(log n).
+//
+// It supports sums only. A Fenwick tree answers a range by subtracting
+// one prefix from another, which min and max cannot undo, and a point
+// update that raises the minimum cannot be folded into the partial
+// results. For range min/max use NewMinSegmentTree or NewMaxSegmentTree,
+// and use NewSumSegmentTree for range updates.
+type FenwickTree[T numeric] struct {
+	tree   []T // 1-based partial sums
+	values []T // current value of each element, for Get and Set
+}
+
+// NewFenwickTree returns a FenwickTree of n zeros.
+func NewFenwickTree[T numeric](n int) *FenwickTree[T] {
+	return &FenwickTree[T]{tree: make([]T, n+1), values: make([]T, n)}
+}
+
+// NewFenwickTreeFrom returns a FenwickTree over a copy of values, built in O(n).
+func NewFenwickTreeFrom[T numeric](values []T) *FenwickTree[T] {
+	ft := &FenwickTree[T]{tree: make([]T, len(values)+1), values: append([]T(nil), values...)}
+	for i, v := range values {
+		j := i + 1
+		ft.tree[j] += v
+		if parent := j + j&-j; parent < len(ft.tree) {
+			ft.tree[parent] += ft.tree[j]
+		}
+	}
+	return ft
+}
+
+// Len returns the number of elements.
+func (ft *FenwickTree[T]) Len() int {
+	return len(ft.values)
+}
+
+func (ft *FenwickTree[T]) checkIndex(i int) {
+	if i < 0 || i >= len(ft.values) {
+		panic(fmt.Sprintf("datastructures: fenwick index %d out of range [0:%d]", i, len(ft.values)))
+	}
+}
+
+// Add adds delta to element i.
+func (ft *FenwickTree[T]) Add(i int, delta T) {
+	ft.checkIndex(i)
+	ft.values[i] += delta
+	for j := i + 1; j < len(ft.tree); j += j & -j {
+		ft.tree[j] += delta
+	}
+}
+
+// Set replaces element i with value.
+func (ft *FenwickTree[T]) Set(i int, value T) {
+	ft.checkIndex(i)
+	ft.Add(i, value-ft.values[i])
+}
+
+// Get returns element i.
+func (ft *FenwickTree[T]) Get(i int) T {
+	ft.checkIndex(i)
+	return ft.values[i]
+}
+
+// PrefixSum returns the sum of elements [0, i).
+func (ft *FenwickTree[T]) PrefixSum(i int) T {
+	if i < 0 || i > len(ft.values) {
+		panic(fmt.Sprintf("datastructures: fenwick prefix %d out of range [0:%d]", i, len(ft.values)))
+	}
+	var sum T
+	for ; i > 0; i -= i & -i {
+		sum += ft.tree[i]
+	}
+	return sum
+}
+
+// RangeSum returns the sum of elements [lo, hi).
+func (ft *FenwickTree[T]) RangeSum(lo, hi int) T {
+	return ft.PrefixSum(hi) - ft.PrefixSum(lo)
+}
+
+// Validate checks that every partial sum equals the sum of the elements
+// it covers. Sums are compared exactly, so a tree of floats may report
+// rounding differences.
+func (ft *FenwickTree[T]) Validate() error {
+	if len(ft.tree) != len(ft.values)+1 {
+		return fmt.Errorf("%w: fenwick tree has %d partial sums for %d elements", ErrInvariant, len(ft.tree)-1, len(ft.values))
+	}
+	for j := 1; j < len(ft.tree); j++ {
+		var sum T
+		for _, v := range ft.values[j-j&-j : j] {
+			sum += v
+		}
+		if ft.tree[j] != sum {
+			return fmt.Errorf("%w: fenwick partial sum %d is %v, want %v", ErrInvariant, j, ft.tree[j], sum)
+		}
+	}
+	return nil
+}
+
+// ======================================================
+// Segment Tree (with Lazy Propagation)
+// ======================================================
+
+// SegmentTree answers range queries under a user-supplied associative
+// combine function, such as sum, min, max or gcd. Point updates and range
+// queries are O(log n). Trees built with NewLazySegmentTree also support
+// O(log n) range updates through lazy propagation.
+type SegmentTree[T any] struct {
+	n       int
+	tree    []T
+	pending []T    // lazy update waiting to be pushed to the children
+	hasLazy []bool // whether pending holds an update
+	combine func(a, b T) T
+	apply   func(node, update T, length int) T
+	compose func(older, newer T) T
+}
+
+// NewSegmentTree builds a SegmentTree over values. combine must be
+// associative; it does not need an identity element.
+func NewSegmentTree[T any](values []T, combine func(a, b T) T) *SegmentTree[T] {
+	st := &SegmentTree[T]{n: len(values), combine: combine}
+	if st.n > 0 {
+		st.tree = make([]T, 4*st.n)
+		st.build(1, 0, st.n, values)
+	}
+	return st
+}
+
+// NewLazySegmentTree builds a SegmentTree that also supports RangeUpdate.
+// apply returns the aggregate of a node covering length elements after
+// update is applied to each of them, and compose merges two updates into
+// one that has the effect of older followed by newer. For range-add on a
+// sum tree: apply = node + update*length, compose = older + newer.
+func NewLazySegmentTree[T any](values []T, combine func(a, b T) T,
+	apply func(node, update T, length int) T, compose func(older, newer T) T) *SegmentTree[T] {
+	st := NewSegmentTree(values, combine)
+	st.apply, st.compose = apply, compose
+	st.pending = make([]T, len(st.tree))
+	st.hasLazy = make([]bool, len(st.tree))
+	return st
+}
+
+// NewSumSegmentTree returns a lazy SegmentTree for range sums with range add.
+func NewSumSegmentTree[T numeric](values []T) *SegmentTree[T] {
+	return NewLazySegmentTree(values,
+		func(a, b T) T { return a + b },
+		func(node, delta T, length int) T { return node + delta*T(length) },
+		func(older, newer T) T { return older + newer })
+}
+
+// NewMinSegmentTree returns a lazy SegmentTree for range minimums with range add.
+func NewMinSegmentTree[T numeric](values []T) *SegmentTree[T] {
+	return NewLazySegmentTree(values,
+		func(a, b T) T { return min(a, b) },
+		func(node, delta T, _ int) T { return node + delta },
+		func(older, newer T) T { return older + newer })
+}
+
+// NewMaxSegmentTree returns a lazy SegmentTree for range maximums with range add.
+func NewMaxSegmentTree[T numeric](values []T) *SegmentTree[T] {
+	return NewLazySegmentTree(values,
+		func(a, b T) T { return max(a, b) },
+		func(node, delta T, _ int) T { return node + delta },
+		func(older, newer T) T { return older + newer })
+}
+
+// Len returns the number of elements.
+func (st *SegmentTree[T]) Len() int {
+	return st.n
+}
+
+func (st *SegmentTree[T]) build(node, l, r int, values []T) {
+	if r-l == 1 {
+		st.tree[node] = values[l]
+		return
+	}
+	mid := (l + r) / 2
+	st.build(2*node, l, mid, values)
+	st.build(2*node+1, mid, r, values)
+	st.tree[node] = st.combine(st.tree[2*node], st.tree[2*node+1])
+}
+
+// applyTo applies update to the node covering [l, r) and records it as
+// pending for the node's children.
+func (st *SegmentTree[T]) applyTo(node, l, r int, update T) {
+	st.tree[node] = st.apply(st.tree[node], update, r-l)
+	if r-l > 1 {
+		if st.hasLazy[node] {
+			st.pending[node] = st.compose(st.pending[node], update)
+		} else {
+			st.pending[node], st.hasLazy[node] = update, true
+		}
+	}
+}
+
+// push hands a pending update down to the children of node.
+func (st *SegmentTree[T]) push(node, l, r int) {
+	if st.hasLazy == nil || !st.hasLazy[node] {
+		return
+	}
+	mid := (l + r) / 2
+	st.applyTo(2*node, l, mid, st.pending[node])
+	st.applyTo(2*node+1, mid, r, st.pending[node])
+	var zero T
+	st.pending[node], st.hasLazy[node] = zero, false
+}
+
+func (st *SegmentTree[T]) checkRange(lo, hi int) {
+	if lo < 0 || hi > st.n || lo >= hi {
+		panic(fmt.Sprintf("datastructures: segment tree range [%d:%d] invalid for length %d", lo, hi, st.n))
+	}
+}
+
+// Query returns the combination of elements [lo, hi). The range must be
+// non-empty, since combine has no identity element.
+func (st *SegmentTree[T]) Query(lo, hi int) T {
+	st.checkRange(lo, hi)
+	v, _ := st.query(1, 0, st.n, lo, hi)
+	return v
+}
+
+func (st *SegmentTree[T]) query(node, l, r, lo, hi int) (T, bool) {
+	if hi <= l || r <= lo {
+		var zero T
+		return zero, false
+	}
+	if lo <= l && r <= hi {
+		return st.tree[node], true
+	}
+	st.push(node, l, r)
+	mid := (l + r) / 2
+	left, okLeft := st.query(2*node, l, mid, lo, hi)
+	right, okRight := st.query(2*node+1, mid, r, lo, hi)
+	switch {
+	case okLeft && okRight:
+		return st.combine(left, right), true
+	case okLeft:
+		return left, true
+	default:
+		return right, okRight
+	}
+}
+
+// Get returns element i.
+func (st *SegmentTree[T]) Get(i int) T {
+	return st.Query(i, i+1)
+}
+
+// Set replaces element i with value.
+func (st *SegmentTree[T]) Set(i int, value T) {
+	st.checkRange(i, i+1)
+	st.set(1, 0, st.n, i, value)
+}
+
+func (st *SegmentTree[T]) set(node, l, r, i int, value T) {
+	if r-l == 1 {
+		st.tree[node] = value
+		return
+	}
+	st.push(node, l, r)
+	mid := (l + r) / 2
+	if i < mid {
+		st.set(2*node, l, mid, i, value)
+	} else {
+		st.set(2*node+1, mid, r, i, value)
+	}
+	st.tree[node] = st.combine(st.tree[2*node], st.tree[2*node+1])
+}
+
+// RangeUpdate applies update to every element in [lo, hi). It panics if
+// the tree was not built with NewLazySegmentTree or one of its helpers.
+func (st *SegmentTree[T]) RangeUpdate(lo, hi int, update T) {
+	if st.apply == nil {
+		panic("datastructures: RangeUpdate requires a lazy segment tree")
+	}
+	st.checkRange(lo, hi)
+	st.rangeUpdate(1, 0, st.n, lo, hi, update)
+}
+
+func (st *SegmentTree[T]) rangeUpdate(node, l, r, lo, hi int, update T) {
+	if hi <= l || r <= lo {
+		return
+	}
+	if lo <= l && r <= hi {
+		st.applyTo(node, l, r, update)
+		return
+	}
+	st.push(node, l, r)
+	mid := (l + r) / 2
+	st.rangeUpdate(2*node, l, mid, lo, hi, update)
+	st.rangeUpdate(2*node+1, mid, r, lo, hi, update)
+	st.tree[node] = st.combine(st.tree[2*node], st.tree[2*node+1])
+}
+
+// Validate checks that every internal node holds the combination of its
+// children with its own pending update applied, and that leaves hold no
+// pending update. Aggregates are compared with reflect.DeepEqual, so a
+// tree of floating-point sums may report rounding differences.
+func (st *SegmentTree[T]) Validate() error {
+	if st.n == 0 {
+		return nil
+	}
+	if len(st.tree) != 4*st.n {
+		return fmt.Errorf("%w: segment tree has %d nodes for %d elements", ErrInvariant, len(st.tree), st.n)
+	}
+	if st.apply != nil && (len(st.pending) != len(st.tree) || len(st.hasLazy) != len(st.tree)) {
+		return fmt.Errorf("%w: segment tree has %d pending updates for %d nodes", ErrInvariant, len(st.pending), len(st.tree))
+	}
+	return st.validate(1, 0, st.n)
+}
+
+func (st *SegmentTree[T]) validate(node, l, r int) error {
+	lazy := st.hasLazy != nil && st.hasLazy[node]
+	if r-l == 1 {
+		if lazy {
+			return fmt.Errorf("%w: segment tree leaf %d has a pending update", ErrInvariant, l)
+		}
+		return nil
+	}
+	mid := (l + r) / 2
+	if err := st.validate(2*node, l, mid); err != nil {
+		return err
+	}
+	if err := st.validate(2*node+1, mid, r); err != nil {
+		return err
+	}
+	want := st.combine(st.tree[2*node], st.tree[2*node+1])
+	if lazy {
+		want = st.apply(want, st.pending[node], r-l)
+	}
+	if !reflect.DeepEqual(st.tree[node], want) {
+		return fmt.Errorf("%w: segment tree node [%d:%d] holds %v, want %v", ErrInvariant, l, r, st.tree[node], want)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package datastructures
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ======================================================
+// Ring Buffer (Fixed Capacity)
+// ======================================================
+
+// RingMode selects what a full RingBuffer does with new items.
+type RingMode int
+
+const (
+	RingOverwrite RingMode = iota // Drop the oldest item to make room
+	RingReject                    // Refuse the new item with ErrRingFull
+)
+
+// ErrRingFull is returned when writing to a full RingBuffer in RingReject mode.
+var ErrRingFull = errors.New("datastructures: ring buffer is full")
+
+// RingBuffer is a FIFO buffer with a fixed capacity, set at creation.
+// Unlike Deque it never grows; unlike container/ring it is typed and
+// knows whether it is full or empty. Use it to keep the last N log lines
+// or samples. It is not safe for concurrent use; see SyncRingBuffer.
+type RingBuffer[T any] struct {
+	buf  []T
+	head int // index of the oldest item
+	n    int
+	mode RingMode
+}
+
+// NewRingBuffer returns an empty RingBuffer. It panics if capacity is not
+// positive.
+func NewRingBuffer[T any](capacity int, mode RingMode) *RingBuffer[T] {
+	if capacity <= 0 {
+		panic(fmt.Sprintf("datastructures: ring buffer capacity %d must be positive", capacity))
+	}
+	return &RingBuffer[T]{buf: make([]T, capacity), mode: mode}
+}
+
+// Len returns the number of items in the buffer.
+func (r *RingBuffer[T]) Len() int { return r.n }
+
+// Cap returns the fixed capacity of the buffer.
+func (r *RingBuffer[T]) Cap() int { return len(r.buf) }
+
+// IsEmpty returns true if the buffer holds no items.
+func (r *RingBuffer[T]) IsEmpty() bool { return r.n == 0 }
+
+// IsFull returns true if the buffer holds Cap items.
+func (r *RingBuffer[T]) IsFull() bool { return r.n == len(r.buf) }
+
+// Mode returns the buffer's behavior when full.
+func (r *RingBuffer[T]) Mode() RingMode { return r.mode }
+
+// Push adds item as the newest entry. When the buffer is full it drops
+// the oldest item with RingOverwrite, or returns ErrRingFull with RingReject.
+func (r *RingBuffer[T]) Push(item T) error {
+	if r.n == len(r.buf) {
+		if r.mode == RingReject {
+			return ErrRingFull
+		}
+		r.buf[r.head] = item
+		r.head = (r.head + 1) % len(r.buf)
+		return nil
+	}
+	r.buf[(r.head+r.n)%len(r.buf)] = item
+	r.n++
+	return nil
+}
+
+// Write pushes items in order and returns how many were accepted. With
+// RingOverwrite all are accepted, and only the last Cap remain if there
+// are more than that. With RingReject it stops when the buffer fills and
+// returns ErrRingFull along with the count written.
+func (r *RingBuffer[T]) Write(items []T) (int, error) {
+	if r.mode == RingOverwrite && len(items) > len(r.buf) {
+		// Everything currently buffered would be overwritten anyway.
+		r.Clear()
+		copy(r.buf, items[len(items)-len(r.buf):])
+		r.n = len(r.buf)
+		return len(items), nil
+	}
+	for i, item := range items {
+		if err := r.Push(item); err != nil {
+			return i, err
+		}
+	}
+	return len(items), nil
+}
+
+// Pop removes and returns the oldest item. Returns false if empty.
+func (r *RingBuffer[T]) Pop() (T, bool) {
+	var zero T
+	if r.n == 0 {
+		return zero, false
+	}
+	item := r.buf[r.head]
+	r.buf[r.head] = zero // Allow the item to be garbage collected
+	r.head = (r.head + 1) % len(r.buf)
+	r.n--
+	return item, true
+}
+
+// Read removes up to len(dst) of the oldest items into dst and returns
+// how many were read.
+func (r *RingBuffer[T]) Read(dst []T) int {
+	count := min(len(dst), r.n)
+	for i := 0; i < count; i++ {
+		dst[i], _ = r.Pop()
+	}
+	return count
+}
+
+// Peek returns the oldest item without removing it. Returns false if empty.
+func (r *RingBuffer[T]) Peek() (T, bool) {
+	if r.n == 0 {
+		var zero T
+		return zero, false
+	}
+	return r.buf[r.head], true
+}
+
+// Newest returns the most recently pushed item. Returns false if empty.
+func (r *RingBuffer[T]) Newest() (T, bool) {
+	if r.n == 0 {
+		var zero T
+		return zero, false
+	}
+	return r.buf[(r.head+r.n-1)%len(r.buf)], true
+}
+
+// At returns the i-th oldest item. It panics if i is out of range.
+func (r *RingBuffer[T]) At(i int) T {
+	if i < 0 || i >= r.n {
+		panic(fmt.Sprintf("datastructures: ring buffer index %d out of range [0:%d]", i, r.n))
+	}
+	return r.buf[(r.head+i)%len(r.buf)]
+}
+
+// Range calls fn for each item from oldest to newest until fn returns false.
+func (r *RingBuffer[T]) Range(fn func(i int, item T) bool) {
+	for i := 0; i < r.n; i++ {
+		if !fn(i, r.buf[(r.head+i)%len(r.buf)]) {
+			return
+		}
+	}
+}
+
+// Slice returns the items from oldest to newest as a new slice.
+func (r *RingBuffer[T]) Slice() []T {
+	items := make([]T, r.n)
+	for i := range items {
+		items[i] = r.buf[(r.head+i)%len(r.buf)]
+	}
+	return items
+}
+
+// Clear removes all items.
+func (r *RingBuffer[T]) Clear() {
+	clear(r.buf)
+	r.head, r.n = 0, 0
+}
+
+// Validate checks that the buffer has a capacity, that head and length
+// fit in it, and that the mode is known.
+func (r *RingBuffer[T]) Validate() error {
+	c := len(r.buf)
+	switch {
+	case c == 0:
+		return fmt.Errorf("%w: ring buffer has no capacity, create it with NewRingBuffer", ErrInvariant)
+	case r.head < 0 || r.head >= c:
+		return fmt.Errorf("%w: ring buffer head %d out of range [0:%d]", ErrInvariant, r.head, c)
+	case r.n < 0 || r.n > c:
+		return fmt.Errorf("%w: ring buffer length %d out of range [0:%d]", ErrInvariant, r.n, c+1)
+	case r.mode != RingOverwrite && r.mode != RingReject:
+		return fmt.Errorf("%w: ring buffer mode %d is unknown", ErrInvariant, r.mode)
+	}
+	return nil
+}
+
+// SyncRingBuffer is a RingBuffer guarded by a mutex, safe for concurrent
+// use. Its methods behave like the RingBuffer methods of the same name.
+type SyncRingBuffer[T any] struct {
+	mu    sync.Mutex
+	inner *RingBuffer[T]
+}
+
+// NewSyncRingBuffer returns an empty SyncRingBuffer. It panics if
+// capacity is not positive.
+func NewSyncRingBuffer[T any](capacity int, mode RingMode) *SyncRingBuffer[T] {
+	return &SyncRingBuffer[T]{inner: NewRingBuffer[T](capacity, mode)}
+}
+
+func (s *SyncRingBuffer[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Len()
+}
+
+func (s *SyncRingBuffer[T]) Cap() int {
+	return s.inner.Cap() // Fixed at creation, no lock needed
+}
+
+func (s *SyncRingBuffer[T]) Push(item T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Push(item)
+}
+
+// Write pushes all items atomically with respect to other callers.
+func (s *SyncRingBuffer[T]) Write(items []T) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Write(items)
+}
+
+func (s *SyncRingBuffer[T]) Pop() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Pop()
+}
+
+func (s *SyncRingBuffer[T]) Read(dst []T) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Read(dst)
+}
+
+func (s *SyncRingBuffer[T]) Peek() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Peek()
+}
+
+func (s *SyncRingBuffer[T]) Newest() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Newest()
+}
+
+// Range iterates over a snapshot taken under the lock, so fn may call
+// back into the buffer.
+func (s *SyncRingBuffer[T]) Range(fn func(i int, item T) bool) {
+	for i, item := range s.Slice() {
+		if !fn(i, item) {
+			return
+		}
+	}
+}
+
+func (s *SyncRingBuffer[T]) Slice() []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inner.Slice()
+}
+
+func (s *SyncRingBuffer[T]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inner.Clear()
+}
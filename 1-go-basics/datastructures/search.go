@@ -0,0 +1,167 @@
+package datastructures
+
+import (
+	"cmp"
+	"math"
+)
+
+// ======================================================
+// Search Algorithms
+// ======================================================
+
+// PartitionPoint returns the first index i in [lo, hi) for which pred(i)
+// is true, or hi if there is none. pred must be monotone over the range:
+// false for a prefix and true for the rest. It makes O(log(hi-lo)) calls.
+func PartitionPoint(lo, hi int, pred func(i int) bool) int {
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1) // Avoid overflow when computing mid
+		if pred(mid) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo
+}
+
+// LowerBound returns the index of the first element of the sorted slice
+// s that is >= target, or len(s) if every element is smaller.
+func LowerBound[T cmp.Ordered](s []T, target T) int {
+	return PartitionPoint(0, len(s), func(i int) bool { return !cmp.Less(s[i], target) })
+}
+
+// UpperBound returns the index of the first element of the sorted slice
+// s that is > target, or len(s) if there is none.
+func UpperBound[T cmp.Ordered](s []T, target T) int {
+	return PartitionPoint(0, len(s), func(i int) bool { return cmp.Less(target, s[i]) })
+}
+
+// EqualRange returns the half-open range [lo, hi) of elements of the
+// sorted slice s that are equal to target. lo == hi if there are none,
+// and lo is then where target would be inserted.
+func EqualRange[T cmp.Ordered](s []T, target T) (lo, hi int) {
+	return LowerBound(s, target), UpperBound(s, target)
+}
+
+// LowerBoundFunc is like LowerBound for a slice sorted by cmp, which
+// compares an element with the target like slices.BinarySearchFunc.
+func LowerBoundFunc[E, T any](s []E, target T, cmp func(E, T) int) int {
+	return PartitionPoint(0, len(s), func(i int) bool { return cmp(s[i], target) >= 0 })
+}
+
+// UpperBoundFunc is like UpperBound for a slice sorted by cmp.
+func UpperBoundFunc[E, T any](s []E, target T, cmp func(E, T) int) int {
+	return PartitionPoint(0, len(s), func(i int) bool { return cmp(s[i], target) > 0 })
+}
+
+// EqualRangeFunc is like EqualRange for a slice sorted by cmp.
+func EqualRangeFunc[E, T any](s []E, target T, cmp func(E, T) int) (lo, hi int) {
+	return LowerBoundFunc(s, target, cmp), UpperBoundFunc(s, target, cmp)
+}
+
+// ExponentialSearch finds target in a sorted source of unknown length.
+// at(i) returns the element at index i, or false if i is past the end.
+// It probes indexes 0, 1, 3, 7, ... until it passes target, then binary
+// searches that window, so it costs O(log i) probes where i is the
+// answer. Returns the lower bound index of target and whether the
+// element there equals target.
+func ExponentialSearch[T cmp.Ordered](at func(i int) (T, bool), target T) (int, bool) {
+	hi := 1
+	for {
+		v, ok := at(hi - 1)
+		if !ok || !cmp.Less(v, target) {
+			break
+		}
+		hi *= 2
+	}
+	lo := hi / 2
+	// Find the end of the source within the window if it is shorter.
+	end := PartitionPoint(lo, hi, func(i int) bool { _, ok := at(i); return !ok })
+	i := PartitionPoint(lo, end, func(i int) bool {
+		v, _ := at(i)
+		return !cmp.Less(v, target)
+	})
+	if i < end {
+		v, _ := at(i)
+		return i, v == target
+	}
+	return i, false
+}
+
+// numeric is the set of types InterpolationSearch can interpolate over.
+type numeric interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// InterpolationSearch finds target in a sorted slice by estimating its
+// position from the values at the ends of the current range. On
+// uniformly distributed keys it takes O(log log n) probes; on skewed
+// keys it degrades towards O(n). Returns the index of target or -1.
+func InterpolationSearch[T numeric](s []T, target T) int {
+	lo, hi := 0, len(s)-1
+	for lo <= hi && target >= s[lo] && target <= s[hi] {
+		if s[hi] == s[lo] {
+			if s[lo] == target {
+				return lo
+			}
+			return -1
+		}
+		// Interpolate in float64 so the arithmetic cannot overflow T. Large
+		// 64-bit keys can round to equal floats, and infinite float keys
+		// give NaN; probe the midpoint then, and clamp rounding errors.
+		pos := lo + (hi-lo)/2
+		span := float64(s[hi]) - float64(s[lo])
+		frac := (float64(target) - float64(s[lo])) / span
+		if span != 0 && !math.IsNaN(frac) && !math.IsInf(frac, 0) {
+			pos = min(max(lo+int(frac*float64(hi-lo)), lo), hi)
+		}
+		switch {
+		case s[pos] == target:
+			return pos
+		case s[pos] < target:
+			lo = pos + 1
+		default:
+			hi = pos - 1
+		}
+	}
+	return -1
+}
+
+// SearchRotated finds target in a sorted slice that has been rotated,
+// such as [4 5 6 1 2 3]. It runs in O(log n) when elements are distinct;
+// runs of duplicates that hide the rotation point make it O(n) in the
+// worst case. Returns the index of target or -1.
+func SearchRotated[T cmp.Ordered](s []T, target T) int {
+	lo, hi := 0, len(s)-1
+	for lo <= hi {
+		mid := lo + (hi-lo)/2
+		switch {
+		case s[mid] == target:
+			return mid
+		case s[lo] == s[mid] && s[mid] == s[hi]:
+			// Cannot tell which half is sorted; shrink both ends.
+			if s[lo] == target {
+				return lo
+			}
+			lo++
+			hi--
+		case s[lo] <= s[mid]:
+			// Left half is sorted.
+			if s[lo] <= target && target < s[mid] {
+				hi = mid - 1
+			} else {
+				lo = mid + 1
+			}
+		default:
+			// Right half is sorted.
+			if s[mid] < target && target <= s[hi] {
+				lo = mid + 1
+			} else {
+				hi = mid - 1
+			}
+		}
+	}
+	return -1
+}
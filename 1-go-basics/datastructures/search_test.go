@@ -0,0 +1,76 @@
+package datastructures
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestInterpolationSearch(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      []int64
+		target int64
+		want   int
+	}{
+		{"empty", nil, 1, -1},
+		{"single hit", []int64{5}, 5, 0},
+		{"single miss", []int64{5}, 4, -1},
+		{"uniform", []int64{10, 20, 30, 40, 50}, 40, 3},
+		{"skewed", []int64{1, 2, 3, 4, 1000}, 4, 3},
+		{"below range", []int64{10, 20, 30}, 5, -1},
+		{"above range", []int64{10, 20, 30}, 35, -1},
+		{"gap", []int64{10, 20, 30}, 25, -1},
+		{"all equal", []int64{7, 7, 7}, 7, 0},
+		// The ends convert to the same float64, which used to give a NaN position.
+		{"large keys", []int64{1 << 62, 1<<62 + 1, 1<<62 + 2}, 1<<62 + 1, 1},
+		{"large keys miss", []int64{1 << 62, 1<<62 + 2, 1<<62 + 4}, 1<<62 + 3, -1},
+		{"extremes", []int64{math.MinInt64, -1, 0, 1, math.MaxInt64}, 1, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InterpolationSearch(tt.s, tt.target); got != tt.want {
+				t.Fatalf("InterpolationSearch(%v, %d) = %d, want %d", tt.s, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterpolationSearchLargeUint64(t *testing.T) {
+	s := make([]uint64, 100)
+	for i := range s {
+		s[i] = math.MaxUint64 - uint64(len(s)-1-i)
+	}
+	for i, v := range s {
+		if got := InterpolationSearch(s, v); got != i {
+			t.Fatalf("InterpolationSearch(%d) = %d, want %d", v, got, i)
+		}
+	}
+}
+
+func TestInterpolationSearchInfinity(t *testing.T) {
+	s := []float64{math.Inf(-1), -1, 0, 2.5, math.Inf(1)}
+	for i, v := range s {
+		if got := InterpolationSearch(s, v); got != i {
+			t.Fatalf("InterpolationSearch(%v) = %d, want %d", v, got, i)
+		}
+	}
+	if got := InterpolationSearch(s, 1); got != -1 {
+		t.Fatalf("InterpolationSearch(1) = %d, want -1", got)
+	}
+}
+
+func TestSearchRotated(t *testing.T) {
+	sorted := []int{1, 2, 3, 4, 5, 6, 7}
+	for shift := range sorted {
+		s := append(slices.Clone(sorted[shift:]), sorted[:shift]...)
+		for i, v := range s {
+			if got := SearchRotated(s, v); got != i {
+				t.Fatalf("SearchRotated(%v, %d) = %d, want %d", s, v, got, i)
+			}
+		}
+		if got := SearchRotated(s, 8); got != -1 {
+			t.Fatalf("SearchRotated(%v, 8) = %d, want -1", s, got)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package datastructures
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+)
+
+// ======================================================
+// Skip List Implementation (Ordered Set with Ranks)
+// ======================================================
+
+const (
+	skipListMaxLevel = 32
+	skipListP        = 0.25 // probability of promoting a node one level up
+)
+
+// skipListLevel is a forward link of a node. span is the number of
+// level-0 steps the link skips, which is what makes rank queries O(log n).
+type skipListLevel[K, V any] struct {
+	forward *skipListNode[K, V]
+	span    int
+}
+
+type skipListNode[K, V any] struct {
+	key      K
+	value    V
+	backward *skipListNode[K, V] // previous node on level 0
+	levels   []skipListLevel[K, V]
+}
+
+// SkipListEntry is a key-value pair returned by SkipList queries.
+type SkipListEntry[K, V any] struct {
+	Key   K
+	Value V
+}
+
+// SkipList is a probabilistic ordered map in the style of a Redis sorted
+// set: insert, delete, search and rank queries are O(log n) on average.
+// Keys are ordered by a comparator, so a composite key such as
+// (score, member) can be used for leaderboards with tied scores.
+// Create one with NewSkipList or NewOrderedSkipList.
+type SkipList[K, V any] struct {
+	compare func(a, b K) int
+	head    *skipListNode[K, V]
+	tail    *skipListNode[K, V]
+	level   int
+	length  int
+	rnd     *rand.Rand
+}
+
+// NewSkipList returns an empty skip list ordered by compare, which must
+// return a negative number, zero or a positive number like cmp.Compare.
+// The seed drives the random level generator, so the same seed and the
+// same operations always build the same list.
+func NewSkipList[K, V any](compare func(a, b K) int, seed int64) *SkipList[K, V] {
+	return &SkipList[K, V]{
+		compare: compare,
+		head:    &skipListNode[K, V]{levels: make([]skipListLevel[K, V], skipListMaxLevel)},
+		level:   1,
+		rnd:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// NewOrderedSkipList returns an empty skip list ordered by the natural
+// order of K.
+func NewOrderedSkipList[K cmp.Ordered, V any](seed int64) *SkipList[K, V] {
+	return NewSkipList[K, V](cmp.Compare[K], seed)
+}
+
+// Len returns the number of entries in the list.
+func (sl *SkipList[K, V]) Len() int {
+	return sl.length
+}
+
+func (sl *SkipList[K, V]) randomLevel() int {
+	level := 1
+	for level < skipListMaxLevel && sl.rnd.Float64() < skipListP {
+		level++
+	}
+	return level
+}
+
+// Set inserts key with value, or replaces the value if key is present.
+func (sl *SkipList[K, V]) Set(key K, value V) {
+	var update [skipListMaxLevel]*skipListNode[K, V]
+	var rank [skipListMaxLevel]int // rank of update[i]
+
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		if i < sl.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.levels[i].forward != nil && sl.compare(x.levels[i].forward.key, key) < 0 {
+			rank[i] += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+	if next := x.levels[0].forward; next != nil && sl.compare(next.key, key) == 0 {
+		next.value = value
+		return
+	}
+
+	level := sl.randomLevel()
+	if level > sl.level {
+		for i := sl.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = sl.head
+			update[i].levels[i].span = sl.length
+		}
+		sl.level = level
+	}
+	x = &skipListNode[K, V]{key: key, value: value, levels: make([]skipListLevel[K, V], level)}
+	for i := 0; i < level; i++ {
+		x.levels[i].forward = update[i].levels[i].forward
+		update[i].levels[i].forward = x
+		x.levels[i].span = update[i].levels[i].span - (rank[0] - rank[i])
+		update[i].levels[i].span = rank[0] - rank[i] + 1
+	}
+	// Links above the new node's height now skip one more node.
+	for i := level; i < sl.level; i++ {
+		update[i].levels[i].span++
+	}
+	if update[0] != sl.head {
+		x.backward = update[0]
+	}
+	if x.levels[0].forward != nil {
+		x.levels[0].forward.backward = x
+	} else {
+		sl.tail = x
+	}
+	sl.length++
+}
+
+// Get returns the value stored under key. Returns false if key is absent.
+func (sl *SkipList[K, V]) Get(key K) (V, bool) {
+	if x := sl.find(key); x != nil {
+		return x.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Contains returns true if key is present.
+func (sl *SkipList[K, V]) Contains(key K) bool {
+	return sl.find(key) != nil
+}
+
+func (sl *SkipList[K, V]) find(key K) *skipListNode[K, V] {
+	x := sl.lowerBound(key)
+	if x != nil && sl.compare(x.key, key) == 0 {
+		return x
+	}
+	return nil
+}
+
+// lowerBound returns the first node whose key is >= key, or nil.
+func (sl *SkipList[K, V]) lowerBound(key K) *skipListNode[K, V] {
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && sl.compare(x.levels[i].forward.key, key) < 0 {
+			x = x.levels[i].forward
+		}
+	}
+	return x.levels[0].forward
+}
+
+// Delete removes key. Returns true if it was present.
+func (sl *SkipList[K, V]) Delete(key K) bool {
+	var update [skipListMaxLevel]*skipListNode[K, V]
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && sl.compare(x.levels[i].forward.key, key) < 0 {
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+	x = x.levels[0].forward
+	if x == nil || sl.compare(x.key, key) != 0 {
+		return false
+	}
+	for i := 0; i < sl.level; i++ {
+		if update[i].levels[i].forward == x {
+			update[i].levels[i].span += x.levels[i].span - 1
+			update[i].levels[i].forward = x.levels[i].forward
+		} else {
+			update[i].levels[i].span--
+		}
+	}
+	if x.levels[0].forward != nil {
+		x.levels[0].forward.backward = x.backward
+	} else {
+		sl.tail = x.backward
+	}
+	for sl.level > 1 && sl.head.levels[sl.level-1].forward == nil {
+		sl.level--
+	}
+	sl.length--
+	return true
+}
+
+// Rank returns the zero-based position of key in ascending order.
+// Returns false if key is absent.
+func (sl *SkipList[K, V]) Rank(key K) (int, bool) {
+	rank := 0
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && sl.compare(x.levels[i].forward.key, key) <= 0 {
+			rank += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		if x != sl.head && sl.compare(x.key, key) == 0 {
+			return rank - 1, true
+		}
+	}
+	return 0, false
+}
+
+// ByRank returns the entry at zero-based position rank in ascending order.
+// Returns false if rank is out of range.
+func (sl *SkipList[K, V]) ByRank(rank int) (K, V, bool) {
+	if x := sl.nodeByRank(rank); x != nil {
+		return x.key, x.value, true
+	}
+	var k K
+	var v V
+	return k, v, false
+}
+
+func (sl *SkipList[K, V]) nodeByRank(rank int) *skipListNode[K, V] {
+	if rank < 0 || rank >= sl.length {
+		return nil
+	}
+	traversed := 0
+	x := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && traversed+x.levels[i].span <= rank+1 {
+			traversed += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		if traversed == rank+1 {
+			return x
+		}
+	}
+	return nil
+}
+
+// Range calls fn in ascending order for every entry with from <= key < to.
+// Iteration stops early if fn returns false.
+func (sl *SkipList[K, V]) Range(from, to K, fn func(key K, value V) bool) {
+	for x := sl.lowerBound(from); x != nil && sl.compare(x.key, to) < 0; x = x.levels[0].forward {
+		if !fn(x.key, x.value) {
+			return
+		}
+	}
+}
+
+// Ascend calls fn for every entry in ascending order.
+// Iteration stops early if fn returns false.
+func (sl *SkipList[K, V]) Ascend(fn func(key K, value V) bool) {
+	for x := sl.head.levels[0].forward; x != nil; x = x.levels[0].forward {
+		if !fn(x.key, x.value) {
+			return
+		}
+	}
+}
+
+// Descend calls fn for every entry in descending order.
+// Iteration stops early if fn returns false.
+func (sl *SkipList[K, V]) Descend(fn func(key K, value V) bool) {
+	for x := sl.tail; x != nil; x = x.backward {
+		if !fn(x.key, x.value) {
+			return
+		}
+	}
+}
+
+// TopN returns up to n entries with the largest keys, largest first.
+func (sl *SkipList[K, V]) TopN(n int) []SkipListEntry[K, V] {
+	entries := make([]SkipListEntry[K, V], 0, min(max(n, 0), sl.length))
+	if n <= 0 {
+		return entries
+	}
+	sl.Descend(func(key K, value V) bool {
+		entries = append(entries, SkipListEntry[K, V]{Key: key, Value: value})
+		return len(entries) < n
+	})
+	return entries
+}
+
+// RevRank returns the zero-based position of key in descending order,
+// so the largest key has rank 0. Returns false if key is absent.
+func (sl *SkipList[K, V]) RevRank(key K) (int, bool) {
+	rank, ok := sl.Rank(key)
+	if !ok {
+		return 0, false
+	}
+	return sl.length - 1 - rank, true
+}
+
+// Validate checks that keys are strictly increasing, that the backward
+// links and tail mirror level 0, that each level links exactly the nodes
+// tall enough for it, and that every span counts the level-0 steps its
+// link skips. A link to nil spans the nodes left after its start.
+func (sl *SkipList[K, V]) Validate() error {
+	rank := map[*skipListNode[K, V]]int{sl.head: 0}
+	tall := make([]int, sl.level) // tall[i] is the number of nodes on level i
+	var prev *skipListNode[K, V]
+	for x := sl.head.levels[0].forward; x != nil; x = x.levels[0].forward {
+		if len(rank) > sl.length {
+			return fmt.Errorf("%w: skip list has more than %d nodes", ErrInvariant, sl.length)
+		}
+		if prev != nil && sl.compare(prev.key, x.key) >= 0 {
+			return fmt.Errorf("%w: skip list key %v follows %v", ErrInvariant, x.key, prev.key)
+		}
+		if x.backward != prev {
+			return fmt.Errorf("%w: skip list key %v has a wrong backward link", ErrInvariant, x.key)
+		}
+		if len(x.levels) > sl.level {
+			return fmt.Errorf("%w: skip list key %v has %d levels, above the list's %d", ErrInvariant, x.key, len(x.levels), sl.level)
+		}
+		for i := range x.levels {
+			tall[i]++
+		}
+		rank[x] = len(rank)
+		prev = x
+	}
+	if len(rank)-1 != sl.length {
+		return fmt.Errorf("%w: skip list has %d nodes but length %d", ErrInvariant, len(rank)-1, sl.length)
+	}
+	if prev != sl.tail {
+		return fmt.Errorf("%w: skip list tail is not its last node", ErrInvariant)
+	}
+	if sl.level > 1 && sl.head.levels[sl.level-1].forward == nil {
+		return fmt.Errorf("%w: skip list level %d is empty", ErrInvariant, sl.level-1)
+	}
+	for i := 0; i < sl.level; i++ {
+		linked := 0
+		for x := sl.head; x != nil; x = x.levels[i].forward {
+			next := x.levels[i].forward
+			want := sl.length - rank[x]
+			if next != nil {
+				r, ok := rank[next]
+				if !ok || r <= rank[x] {
+					return fmt.Errorf("%w: skip list level %d links key %v out of order", ErrInvariant, i, next.key)
+				}
+				want = r - rank[x]
+				linked++
+			}
+			if x.levels[i].span != want {
+				return fmt.Errorf("%w: skip list level %d link at rank %d spans %d, want %d", ErrInvariant, i, rank[x], x.levels[i].span, want)
+			}
+		}
+		if linked != tall[i] {
+			return fmt.Errorf("%w: skip list level %d links %d of its %d nodes", ErrInvariant, i, linked, tall[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,364 @@
+package datastructures
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// ======================================================
+// TreeMap Implementation (AVL Tree)
+// ======================================================
+
+// treeMapNode is a node of the AVL tree backing a TreeMap.
+// size is the number of nodes in the subtree rooted at this node
+// and is what makes Rank and Select O(log n).
+type treeMapNode[K cmp.Ordered, V any] struct {
+	key    K
+	value  V
+	left   *treeMapNode[K, V]
+	right  *treeMapNode[K, V]
+	height int
+	size   int
+}
+
+// TreeMap is an ordered map kept balanced as an AVL tree, so every
+// operation stays O(log n) even when keys are inserted in sorted order.
+// The zero value is an empty map ready to use.
+type TreeMap[K cmp.Ordered, V any] struct {
+	root *treeMapNode[K, V]
+}
+
+func nodeHeight[K cmp.Ordered, V any](n *treeMapNode[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func nodeSize[K cmp.Ordered, V any](n *treeMapNode[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *treeMapNode[K, V]) update() {
+	n.height = 1 + max(nodeHeight(n.left), nodeHeight(n.right))
+	n.size = 1 + nodeSize(n.left) + nodeSize(n.right)
+}
+
+func (n *treeMapNode[K, V]) balanceFactor() int {
+	return nodeHeight(n.left) - nodeHeight(n.right)
+}
+
+func rotateRight[K cmp.Ordered, V any](n *treeMapNode[K, V]) *treeMapNode[K, V] {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.update()
+	l.update()
+	return l
+}
+
+func rotateLeft[K cmp.Ordered, V any](n *treeMapNode[K, V]) *treeMapNode[K, V] {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.update()
+	r.update()
+	return r
+}
+
+// rebalance restores the AVL property at n after one of its subtrees changed.
+func rebalance[K cmp.Ordered, V any](n *treeMapNode[K, V]) *treeMapNode[K, V] {
+	n.update()
+	switch bf := n.balanceFactor(); {
+	case bf > 1:
+		if n.left.balanceFactor() < 0 {
+			n.left = rotateLeft(n.left)
+		}
+		return rotateRight(n)
+	case bf < -1:
+		if n.right.balanceFactor() > 0 {
+			n.right = rotateRight(n.right)
+		}
+		return rotateLeft(n)
+	}
+	return n
+}
+
+// Len returns the number of entries in the map.
+func (t *TreeMap[K, V]) Len() int {
+	return nodeSize(t.root)
+}
+
+// Put inserts or replaces the value stored under key.
+func (t *TreeMap[K, V]) Put(key K, value V) {
+	t.root = putNode(t.root, key, value)
+}
+
+func putNode[K cmp.Ordered, V any](n *treeMapNode[K, V], key K, value V) *treeMapNode[K, V] {
+	if n == nil {
+		return &treeMapNode[K, V]{key: key, value: value, height: 1, size: 1}
+	}
+	switch c := cmp.Compare(key, n.key); {
+	case c < 0:
+		n.left = putNode(n.left, key, value)
+	case c > 0:
+		n.right = putNode(n.right, key, value)
+	default:
+		n.value = value
+		return n
+	}
+	return rebalance(n)
+}
+
+// Get returns the value stored under key. Returns false if the key is absent.
+func (t *TreeMap[K, V]) Get(key K) (V, bool) {
+	n := t.root
+	for n != nil {
+		switch c := cmp.Compare(key, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n.value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Contains returns true if key is present in the map.
+func (t *TreeMap[K, V]) Contains(key K) bool {
+	_, ok := t.Get(key)
+	return ok
+}
+
+// Delete removes key from the map. Returns true if the key was present.
+func (t *TreeMap[K, V]) Delete(key K) bool {
+	var deleted bool
+	t.root = deleteNode(t.root, key, &deleted)
+	return deleted
+}
+
+func deleteNode[K cmp.Ordered, V any](n *treeMapNode[K, V], key K, deleted *bool) *treeMapNode[K, V] {
+	if n == nil {
+		return nil
+	}
+	switch c := cmp.Compare(key, n.key); {
+	case c < 0:
+		n.left = deleteNode(n.left, key, deleted)
+	case c > 0:
+		n.right = deleteNode(n.right, key, deleted)
+	default:
+		*deleted = true
+		if n.left == nil {
+			return n.right
+		}
+		if n.right == nil {
+			return n.left
+		}
+		// Replace n with its in-order successor.
+		succ := n.right
+		for succ.left != nil {
+			succ = succ.left
+		}
+		n.key, n.value = succ.key, succ.value
+		var removed bool
+		n.right = deleteNode(n.right, succ.key, &removed)
+	}
+	return rebalance(n)
+}
+
+// Min returns the smallest key and its value. Returns false if the map is empty.
+func (t *TreeMap[K, V]) Min() (K, V, bool) {
+	if t.root == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	n := t.root
+	for n.left != nil {
+		n = n.left
+	}
+	return n.key, n.value, true
+}
+
+// Max returns the largest key and its value. Returns false if the map is empty.
+func (t *TreeMap[K, V]) Max() (K, V, bool) {
+	if t.root == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	n := t.root
+	for n.right != nil {
+		n = n.right
+	}
+	return n.key, n.value, true
+}
+
+// Floor returns the largest key less than or equal to key.
+// Returns false if there is no such key.
+func (t *TreeMap[K, V]) Floor(key K) (K, V, bool) {
+	var best *treeMapNode[K, V]
+	n := t.root
+	for n != nil {
+		switch c := cmp.Compare(key, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			best = n
+			n = n.right
+		default:
+			return n.key, n.value, true
+		}
+	}
+	if best == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	return best.key, best.value, true
+}
+
+// Ceiling returns the smallest key greater than or equal to key.
+// Returns false if there is no such key.
+func (t *TreeMap[K, V]) Ceiling(key K) (K, V, bool) {
+	var best *treeMapNode[K, V]
+	n := t.root
+	for n != nil {
+		switch c := cmp.Compare(key, n.key); {
+		case c < 0:
+			best = n
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n.key, n.value, true
+		}
+	}
+	if best == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	return best.key, best.value, true
+}
+
+// Rank returns the number of keys strictly less than key.
+func (t *TreeMap[K, V]) Rank(key K) int {
+	rank := 0
+	n := t.root
+	for n != nil {
+		switch c := cmp.Compare(key, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			rank += nodeSize(n.left) + 1
+			n = n.right
+		default:
+			return rank + nodeSize(n.left)
+		}
+	}
+	return rank
+}
+
+// Select returns the entry with the given zero-based rank, so Select(0)
+// is the minimum. Returns false if i is out of range.
+func (t *TreeMap[K, V]) Select(i int) (K, V, bool) {
+	if i < 0 || i >= t.Len() {
+		var k K
+		var v V
+		return k, v, false
+	}
+	n := t.root
+	for {
+		left := nodeSize(n.left)
+		switch {
+		case i < left:
+			n = n.left
+		case i > left:
+			i -= left + 1
+			n = n.right
+		default:
+			return n.key, n.value, true
+		}
+	}
+}
+
+// Ascend calls fn for every entry in ascending key order.
+// Iteration stops early if fn returns false.
+func (t *TreeMap[K, V]) Ascend(fn func(key K, value V) bool) {
+	ascendRange(t.root, nil, nil, fn)
+}
+
+// Range calls fn in ascending key order for every entry with
+// from <= key < to. Iteration stops early if fn returns false.
+func (t *TreeMap[K, V]) Range(from, to K, fn func(key K, value V) bool) {
+	ascendRange(t.root, &from, &to, fn)
+}
+
+// ascendRange walks the subtree in order, skipping subtrees that lie
+// entirely outside [from, to). A nil bound means unbounded.
+func ascendRange[K cmp.Ordered, V any](n *treeMapNode[K, V], from, to *K, fn func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	if from == nil || n.key > *from {
+		if !ascendRange(n.left, from, to, fn) {
+			return false
+		}
+	}
+	if (from == nil || n.key >= *from) && (to == nil || n.key < *to) {
+		if !fn(n.key, n.value) {
+			return false
+		}
+	}
+	if to == nil || n.key < *to {
+		return ascendRange(n.right, from, to, fn)
+	}
+	return true
+}
+
+// InOrderTraversal returns the keys of the map in ascending order.
+func (t *TreeMap[K, V]) InOrderTraversal() []K {
+	keys := make([]K, 0, t.Len())
+	t.Ascend(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Validate checks that keys are in order and that every node's height,
+// size and balance factor are correct.
+func (t *TreeMap[K, V]) Validate() error {
+	return validateTreeMapNode(t.root, nil, nil)
+}
+
+func validateTreeMapNode[K cmp.Ordered, V any](n *treeMapNode[K, V], lo, hi *K) error {
+	if n == nil {
+		return nil
+	}
+	if (lo != nil && n.key <= *lo) || (hi != nil && n.key >= *hi) {
+		return fmt.Errorf("%w: treemap key %v is on the wrong side of an ancestor", ErrInvariant, n.key)
+	}
+	if err := validateTreeMapNode(n.left, lo, &n.key); err != nil {
+		return err
+	}
+	if err := validateTreeMapNode(n.right, &n.key, hi); err != nil {
+		return err
+	}
+	switch {
+	case n.height != 1+max(nodeHeight(n.left), nodeHeight(n.right)):
+		return fmt.Errorf("%w: treemap key %v has height %d", ErrInvariant, n.key, n.height)
+	case n.size != 1+nodeSize(n.left)+nodeSize(n.right):
+		return fmt.Errorf("%w: treemap key %v has size %d", ErrInvariant, n.key, n.size)
+	case n.balanceFactor() < -1 || n.balanceFactor() > 1:
+		return fmt.Errorf("%w: treemap key %v has balance factor %d", ErrInvariant, n.key, n.balanceFactor())
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package datastructures
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"unicode/utf8"
+)
+
+// ======================================================
+// Trie Implementation (Prefix Tree)
+// ======================================================
+
+// trieNode is a node of a Trie. Children are keyed by rune, so words are
+// split into Unicode code points rather than bytes.
+type trieNode struct {
+	children map[rune]*trieNode
+	terminal bool // a word ends at this node
+	weight   int  // ranking weight of the word ending here
+}
+
+// Trie is a prefix tree of words with a ranking weight per word.
+// It works on runes, so non-ASCII text is matched by code point.
+// The zero value is an empty trie ready to use.
+type Trie struct {
+	root trieNode
+	size int
+}
+
+// Completion is a word stored in a Trie together with its weight.
+type Completion struct {
+	Word   string
+	Weight int
+}
+
+// Len returns the number of words in the trie.
+func (t *Trie) Len() int {
+	return t.size
+}
+
+// Insert adds word with the given weight, replacing the weight if the
+// word is already present.
+func (t *Trie) Insert(word string, weight int) {
+	n := &t.root
+	for _, r := range word {
+		child, ok := n.children[r]
+		if !ok {
+			if n.children == nil {
+				n.children = make(map[rune]*trieNode)
+			}
+			child = &trieNode{}
+			n.children[r] = child
+		}
+		n = child
+	}
+	if !n.terminal {
+		t.size++
+	}
+	n.terminal = true
+	n.weight = weight
+}
+
+// find returns the node reached by following s, or nil.
+func (t *Trie) find(s string) *trieNode {
+	n := &t.root
+	for _, r := range s {
+		n = n.children[r]
+		if n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// Get returns the weight of word. Returns false if word is not in the trie.
+func (t *Trie) Get(word string) (int, bool) {
+	n := t.find(word)
+	if n == nil || !n.terminal {
+		return 0, false
+	}
+	return n.weight, true
+}
+
+// Contains returns true if word is in the trie.
+func (t *Trie) Contains(word string) bool {
+	_, ok := t.Get(word)
+	return ok
+}
+
+// HasPrefix returns true if any word in the trie starts with prefix.
+func (t *Trie) HasPrefix(prefix string) bool {
+	n := t.find(prefix)
+	return n != nil && (n.terminal || len(n.children) > 0)
+}
+
+// Delete removes word from the trie and prunes nodes that no longer lead
+// to any word. Returns true if the word was present.
+func (t *Trie) Delete(word string) bool {
+	runes := []rune(word)
+	path := make([]*trieNode, 0, len(runes)+1)
+	n := &t.root
+	path = append(path, n)
+	for _, r := range runes {
+		n = n.children[r]
+		if n == nil {
+			return false
+		}
+		path = append(path, n)
+	}
+	if !n.terminal {
+		return false
+	}
+	n.terminal = false
+	n.weight = 0
+	t.size--
+	// Walk back up, removing empty leaves.
+	for i := len(runes); i > 0; i-- {
+		node := path[i]
+		if node.terminal || len(node.children) > 0 {
+			break
+		}
+		delete(path[i-1].children, runes[i-1])
+	}
+	return true
+}
+
+// WithPrefix returns every word that starts with prefix, in lexicographic
+// (code point) order.
+func (t *Trie) WithPrefix(prefix string) []string {
+	var words []string
+	n := t.find(prefix)
+	if n == nil {
+		return words
+	}
+	collectWords(n, []rune(prefix), func(word []rune, _ int) {
+		words = append(words, string(word))
+	})
+	return words
+}
+
+// collectWords walks the subtree below n depth-first with children in
+// rune order, calling emit for every word. prefix is the path to n.
+func collectWords(n *trieNode, prefix []rune, emit func(word []rune, weight int)) {
+	if n.terminal {
+		emit(prefix, n.weight)
+	}
+	keys := make([]rune, 0, len(n.children))
+	for r := range n.children {
+		keys = append(keys, r)
+	}
+	slices.Sort(keys)
+	for _, r := range keys {
+		collectWords(n.children[r], append(prefix, r), emit)
+	}
+}
+
+// LongestPrefixOf returns the longest word in the trie that is a prefix
+// of s. Returns false if no word is a prefix of s.
+func (t *Trie) LongestPrefixOf(s string) (string, bool) {
+	n := &t.root
+	end, found := 0, n.terminal
+	for i := 0; i < len(s); {
+		r, width := utf8.DecodeRuneInString(s[i:])
+		i += width
+		n = n.children[r]
+		if n == nil {
+			break
+		}
+		if n.terminal {
+			end, found = i, true
+		}
+	}
+	return s[:end], found
+}
+
+// TopK returns up to k words starting with prefix, ranked by weight from
+// highest to lowest. Words with equal weight are ordered lexicographically.
+func (t *Trie) TopK(prefix string, k int) []Completion {
+	var completions []Completion
+	n := t.find(prefix)
+	if n == nil || k <= 0 {
+		return completions
+	}
+	collectWords(n, []rune(prefix), func(word []rune, weight int) {
+		completions = append(completions, Completion{Word: string(word), Weight: weight})
+	})
+	// collectWords yields words in lexicographic order, so a stable sort
+	// by weight keeps that order among ties.
+	sort.SliceStable(completions, func(i, j int) bool {
+		return completions[i].Weight > completions[j].Weight
+	})
+	if len(completions) > k {
+		completions = completions[:k]
+	}
+	return completions
+}
+
+// Validate checks that the word count matches the terminal nodes, that
+// only terminal nodes carry a weight, and that every node below the root
+// leads to a word, so Delete left nothing to prune.
+func (t *Trie) Validate() error {
+	words, err := validateTrieNode(&t.root, nil)
+	if err != nil {
+		return err
+	}
+	if words != t.size {
+		return fmt.Errorf("%w: trie holds %d words but size %d", ErrInvariant, words, t.size)
+	}
+	return nil
+}
+
+// validateTrieNode checks the subtree of n, reached by prefix, and
+// returns the number of words in it.
+func validateTrieNode(n *trieNode, prefix []rune) (int, error) {
+	words := 0
+	if n.terminal {
+		words++
+	} else if n.weight != 0 {
+		return 0, fmt.Errorf("%w: trie prefix %q is not a word but has weight %d", ErrInvariant, string(prefix), n.weight)
+	}
+	if len(prefix) > 0 && !n.terminal && len(n.children) == 0 {
+		return 0, fmt.Errorf("%w: trie prefix %q leads to no word", ErrInvariant, string(prefix))
+	}
+	for r, child := range n.children {
+		if child == nil {
+			return 0, fmt.Errorf("%w: trie prefix %q has a nil child", ErrInvariant, string(prefix)+string(r))
+		}
+		w, err := validateTrieNode(child, append(prefix[:len(prefix):len(prefix)], r))
+		if err != nil {
+			return 0, err
+		}
+		words += w
+	}
+	return words, nil
+}
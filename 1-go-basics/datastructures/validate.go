@@ -0,0 +1,11 @@
+package datastructures
+
+import "errors"
+
+// ======================================================
+// Invariant Checking
+// ======================================================
+
+// ErrInvariant is wrapped by the errors Validate methods return when a
+// structure's internal invariants do not hold.
+var ErrInvariant = errors.New("datastructures: invariant violated")
@@ -0,0 +1,830 @@
+package datastructures
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+// The fuzz targets below interpret their input as a sequence of
+// operations, two bytes each, and replay it against one structure. After
+// every operation they call Validate and compare the structure with a
+// simple reference model. Run one with, for example:
+//
+//	go test -fuzz FuzzTreeMap ./datastructures
+//
+// Plain go test runs only the seed inputs.
+
+// errModelMismatch reports that a structure disagrees with the plain
+// slice or map the fuzz targets run alongside it.
+var errModelMismatch = errors.New("datastructures: result differs from the reference model")
+
+// runOps calls step for each (op, arg) byte pair in data.
+func runOps(name string, data []byte, step func(op, arg byte) error) error {
+	for i := 0; i+1 < len(data); i += 2 {
+		if err := step(data[i], data[i+1]); err != nil {
+			return fmt.Errorf("%s, operation %d: %w", name, i/2+1, err)
+		}
+	}
+	return nil
+}
+
+func checkLinkedListOps(data []byte) error {
+	var l LinkedList[byte]
+	var model []byte
+	return runOps("LinkedList", data, func(op, arg byte) error {
+		switch op % 6 {
+		case 0:
+			l.Append(arg)
+			model = append(model, arg)
+		case 1:
+			l.Prepend(arg)
+			model = slices.Insert(model, 0, arg)
+		case 2:
+			i := int(arg) % (len(model) + 1)
+			l.InsertAt(i, arg)
+			model = slices.Insert(model, i, arg)
+		case 3:
+			if len(model) > 0 {
+				i := int(arg) % len(model)
+				l.RemoveAt(i)
+				model = slices.Delete(model, i, i+1)
+			}
+		case 4:
+			l.Reverse()
+			slices.Reverse(model)
+		case 5:
+			i := int(arg) % (len(model) + 1)
+			l.SplitAt(i)
+			model = model[:i]
+		}
+		if err := l.Validate(); err != nil {
+			return err
+		}
+		i := 0
+		for current := l.Head; current != nil; current = current.Next {
+			if i >= len(model) || current.Data != model[i] {
+				return errModelMismatch
+			}
+			i++
+		}
+		return nil
+	})
+}
+
+func checkDoublyLinkedListOps(data []byte) error {
+	var l DoublyLinkedList[byte]
+	var model []byte
+	return runOps("DoublyLinkedList", data, func(op, arg byte) error {
+		switch op % 6 {
+		case 0:
+			l.PushBack(arg)
+			model = append(model, arg)
+		case 1:
+			l.PushFront(arg)
+			model = slices.Insert(model, 0, arg)
+		case 2:
+			if _, ok := l.PopFront(); ok {
+				model = model[1:]
+			}
+		case 3:
+			if _, ok := l.PopBack(); ok {
+				model = model[:len(model)-1]
+			}
+		case 4:
+			if len(model) > 0 {
+				l.MoveToBack(l.Front())
+				model = append(model[1:], model[0])
+			}
+		case 5:
+			l.Reverse()
+			slices.Reverse(model)
+		}
+		if err := l.Validate(); err != nil {
+			return err
+		}
+		if !slices.Equal(l.Values(), model) {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkBSTOps(data []byte) error {
+	var bst BinarySearchTree
+	var model []int
+	return runOps("BinarySearchTree", data, func(op, arg byte) error {
+		value := int(arg % 64)
+		if op%2 == 0 {
+			bst.Insert(value)
+			model = append(model, value)
+			sort.Ints(model)
+		}
+		if err := bst.Validate(); err != nil {
+			return err
+		}
+		_, found := slices.BinarySearch(model, value)
+		if bst.Search(value) != found || !slices.Equal(bst.InOrderTraversal(), model) {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkPriorityQueueOps(data []byte) error {
+	var pq PriorityQueue
+	return runOps("PriorityQueue", data, func(op, arg byte) error {
+		switch op % 3 {
+		case 0:
+			heap.Push(&pq, &PriorityQueueItem{Value: arg, Priority: int(arg)})
+		case 1:
+			if len(pq) > 0 {
+				lowest := slices.MinFunc(pq, func(a, b *PriorityQueueItem) int { return a.Priority - b.Priority })
+				if heap.Pop(&pq).(*PriorityQueueItem).Priority != lowest.Priority {
+					return errModelMismatch
+				}
+			}
+		case 2:
+			if len(pq) > 0 {
+				pq.Update(pq[int(arg)%len(pq)], arg, int(op))
+			}
+		}
+		return pq.Validate()
+	})
+}
+
+func checkPQOps(data []byte) error {
+	pq := NewMinPQ[byte]()
+	var handles []*PQHandle[byte]
+	return runOps("PQ", data, func(op, arg byte) error {
+		switch op % 4 {
+		case 0:
+			handles = append(handles, pq.Push(arg, int(arg)))
+		case 1:
+			if len(handles) > 0 {
+				lowest := slices.MinFunc(handles, func(a, b *PQHandle[byte]) int { return a.priority - b.priority })
+				if _, priority, _ := pq.Pop(); priority != lowest.priority {
+					return errModelMismatch
+				}
+				handles = slices.DeleteFunc(handles, func(h *PQHandle[byte]) bool { return h.index < 0 })
+			}
+		case 2:
+			if len(handles) > 0 {
+				pq.Update(handles[int(arg)%len(handles)], int(op))
+			}
+		case 3:
+			if len(handles) > 0 {
+				i := int(arg) % len(handles)
+				pq.Remove(handles[i])
+				handles = slices.Delete(handles, i, i+1)
+			}
+		}
+		if err := pq.Validate(); err != nil {
+			return err
+		}
+		if pq.Len() != len(handles) {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkTreeMapOps(data []byte) error {
+	var t TreeMap[byte, byte]
+	model := make(map[byte]byte)
+	return runOps("TreeMap", data, func(op, arg byte) error {
+		key := arg % 32
+		if op%2 == 0 {
+			t.Put(key, op)
+			model[key] = op
+		} else {
+			t.Delete(key)
+			delete(model, key)
+		}
+		if err := t.Validate(); err != nil {
+			return err
+		}
+		if value, ok := t.Get(key); t.Len() != len(model) || ok != (op%2 == 0) || (ok && value != model[key]) {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkIntervalTreeOps(data []byte) error {
+	var t IntervalTree[int, byte]
+	model := make(map[Interval[int]]int)
+	return runOps("IntervalTree", data, func(op, arg byte) error {
+		start := int(arg % 32)
+		iv := Interval[int]{Start: start, End: start + 1 + int(op%8)}
+		if op%16 < 8 {
+			t.Insert(iv.Start, iv.End, op)
+			model[iv]++
+		} else if t.Delete(iv.Start, iv.End) != (model[iv] > 0) {
+			return errModelMismatch
+		} else if model[iv] > 0 {
+			model[iv]--
+		}
+		if err := t.Validate(); err != nil {
+			return err
+		}
+		overlapping := 0
+		for other, count := range model {
+			if other.Overlaps(iv) {
+				overlapping += count
+			}
+		}
+		if len(t.QueryRange(iv.Start, iv.End)) != overlapping {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkSkipListOps(data []byte) error {
+	sl := NewOrderedSkipList[byte, byte](1)
+	model := make(map[byte]bool)
+	return runOps("SkipList", data, func(op, arg byte) error {
+		key := arg % 64
+		if op%3 < 2 {
+			sl.Set(key, op)
+			model[key] = true
+		} else if sl.Delete(key) != model[key] {
+			return errModelMismatch
+		} else {
+			delete(model, key)
+		}
+		if err := sl.Validate(); err != nil {
+			return err
+		}
+		if sl.Len() != len(model) {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkDequeOps(data []byte) error {
+	var d Deque[byte]
+	var model []byte
+	return runOps("Deque", data, func(op, arg byte) error {
+		switch op % 5 {
+		case 0:
+			d.PushBack(arg)
+			model = append(model, arg)
+		case 1:
+			d.PushFront(arg)
+			model = slices.Insert(model, 0, arg)
+		case 2:
+			if item, ok := d.PopFront(); ok {
+				if item != model[0] {
+					return errModelMismatch
+				}
+				model = model[1:]
+			}
+		case 3:
+			if item, ok := d.PopBack(); ok {
+				if item != model[len(model)-1] {
+					return errModelMismatch
+				}
+				model = model[:len(model)-1]
+			}
+		case 4:
+			if arg%8 == 0 {
+				d.Clear()
+				model = nil
+			}
+		}
+		if err := d.Validate(); err != nil {
+			return err
+		}
+		if d.Len() != len(model) {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkRingBufferOps(data []byte) error {
+	mode := RingOverwrite
+	if len(data) > 0 && data[0]%2 == 1 {
+		mode = RingReject
+	}
+	r := NewRingBuffer[byte](5, mode)
+	var model []byte
+	return runOps("RingBuffer", data, func(op, arg byte) error {
+		switch op % 3 {
+		case 0, 1:
+			if err := r.Push(arg); err == nil {
+				model = append(model, arg)
+				if len(model) > r.Cap() {
+					model = model[1:]
+				}
+			}
+		case 2:
+			if item, ok := r.Pop(); ok {
+				if item != model[0] {
+					return errModelMismatch
+				}
+				model = model[1:]
+			}
+		}
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		if !slices.Equal(r.Slice(), model) {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkDisjointSetOps(data []byte) error {
+	ds := NewDisjointSet(4)
+	model := []int{0, 1, 2, 3} // component label of each element
+	return runOps("DisjointSet", data, func(op, arg byte) error {
+		if op%4 == 0 {
+			ds.Add()
+			model = append(model, len(model))
+		} else {
+			a, b := int(op)%len(model), int(arg)%len(model)
+			if ds.Union(a, b) != (model[a] != model[b]) {
+				return errModelMismatch
+			}
+			from, to := model[b], model[a]
+			for i, label := range model {
+				if label == from {
+					model[i] = to
+				}
+			}
+		}
+		if err := ds.Validate(); err != nil {
+			return err
+		}
+		labels := make(map[int]bool)
+		for _, label := range model {
+			labels[label] = true
+		}
+		if ds.Count() != len(labels) {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkDelayQueueOps(data []byte) error {
+	start := time.Unix(0, 0)
+	clock := NewManualClock(start)
+	q := NewDelayQueue[byte](clock)
+	var handles []*DelayHandle[byte]
+	return runOps("DelayQueue", data, func(op, arg byte) error {
+		switch op % 4 {
+		case 0, 1:
+			handles = append(handles, q.PushAfter(arg, time.Duration(arg%16)*time.Second))
+		case 2:
+			clock.Advance(time.Duration(arg%4) * time.Second)
+			if value, ok := q.Poll(); ok {
+				i := slices.IndexFunc(handles, func(h *DelayHandle[byte]) bool { return h.index < 0 })
+				if i < 0 || handles[i].value != value || handles[i].deadline.After(clock.Now()) {
+					return errModelMismatch
+				}
+				handles = slices.Delete(handles, i, i+1)
+			}
+		case 3:
+			if len(handles) > 0 {
+				i := int(arg) % len(handles)
+				if !q.Cancel(handles[i]) {
+					return errModelMismatch
+				}
+				handles = slices.Delete(handles, i, i+1)
+			}
+		}
+		if err := q.Validate(); err != nil {
+			return err
+		}
+		if q.Len() != len(handles) {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkFenwickTreeOps(data []byte) error {
+	model := make([]int, 13)
+	ft := NewFenwickTreeFrom(model)
+	return runOps("FenwickTree", data, func(op, arg byte) error {
+		i := int(arg) % len(model)
+		switch op % 3 {
+		case 0:
+			ft.Add(i, int(op)-128)
+			model[i] += int(op) - 128
+		case 1:
+			ft.Set(i, int(op))
+			model[i] = int(op)
+		}
+		if err := ft.Validate(); err != nil {
+			return err
+		}
+		lo, hi := min(i, int(op)%len(model)), max(i, int(op)%len(model))
+		sum := 0
+		for _, v := range model[lo:hi] {
+			sum += v
+		}
+		if ft.RangeSum(lo, hi) != sum || ft.Get(i) != model[i] {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkSegmentTreeOps(data []byte) error {
+	model := make([]int, 13)
+	trees := []*SegmentTree[int]{NewSumSegmentTree(model), NewMinSegmentTree(model), NewMaxSegmentTree(model)}
+	return runOps("SegmentTree", data, func(op, arg byte) error {
+		lo, hi := int(arg)%len(model), int(op)%len(model)
+		lo, hi = min(lo, hi), max(lo, hi)+1
+		switch op % 3 {
+		case 0:
+			for _, st := range trees {
+				st.Set(lo, int(arg))
+			}
+			model[lo] = int(arg)
+		case 1:
+			delta := int(arg) - 128
+			for _, st := range trees {
+				st.RangeUpdate(lo, hi, delta)
+			}
+			for i := lo; i < hi; i++ {
+				model[i] += delta
+			}
+		}
+		sum := 0
+		for _, v := range model[lo:hi] {
+			sum += v
+		}
+		want := []int{sum, slices.Min(model[lo:hi]), slices.Max(model[lo:hi])}
+		for i, st := range trees {
+			if err := st.Validate(); err != nil {
+				return err
+			}
+			if st.Query(lo, hi) != want[i] {
+				return errModelMismatch
+			}
+		}
+		return nil
+	})
+}
+
+func checkTrieOps(data []byte) error {
+	var t Trie
+	model := make(map[string]int)
+	alphabet := []rune{'a', 'b', 'é', '本'}
+	return runOps("Trie", data, func(op, arg byte) error {
+		// The top two bits of arg give the length, the rest the runes.
+		word := make([]rune, arg>>6)
+		for i := range word {
+			word[i] = alphabet[arg>>(2*i)&3]
+		}
+		w := string(word)
+		switch op % 3 {
+		case 0:
+			t.Insert(w, int(op))
+			model[w] = int(op)
+		case 1:
+			_, ok := model[w]
+			if t.Delete(w) != ok {
+				return errModelMismatch
+			}
+			delete(model, w)
+		}
+		if err := t.Validate(); err != nil {
+			return err
+		}
+		weight, ok := t.Get(w)
+		if want, inModel := model[w]; t.Len() != len(model) || ok != inModel || weight != want {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkPVectorOps(data []byte) error {
+	// Every operation derives a new version from an old one, and all
+	// versions are checked against their own model.
+	versions := []*PVector[byte]{NewPVector[byte]()}
+	models := [][]byte{nil}
+	return runOps("PVector", data, func(op, arg byte) error {
+		from := int(op) % len(versions)
+		v, model := versions[from], slices.Clone(models[from])
+		switch op % 3 {
+		case 0:
+			// Append in batches so the trie grows more than one level.
+			for i := 0; i < int(arg)*4; i++ {
+				v = v.Append(byte(i))
+				model = append(model, byte(i))
+			}
+		case 1:
+			if len(model) > 0 {
+				i := int(arg) % len(model)
+				v = v.Set(i, op)
+				model[i] = op
+			}
+		case 2:
+			for i := 0; i < int(arg)%64 && len(model) > 0; i++ {
+				v = v.Pop()
+				model = model[:len(model)-1]
+			}
+		}
+		if len(versions) < 8 {
+			versions, models = append(versions, v), append(models, model)
+		} else {
+			versions[int(arg)%8], models[int(arg)%8] = v, model
+		}
+		for i, v := range versions {
+			if err := v.Validate(); err != nil {
+				return err
+			}
+			if v.Len() != len(models[i]) || !slices.Equal(v.Slice(), models[i]) {
+				return errModelMismatch
+			}
+		}
+		return nil
+	})
+}
+
+func checkPMapOps(data []byte) error {
+	// The second map hashes keys to only eight values, so keys collide
+	// fully and end up in collision nodes.
+	current := []*PMap[byte, byte]{
+		NewPMap[byte, byte](),
+		NewPMapFunc[byte, byte](func(k byte) uint64 { return uint64(k % 8) }),
+	}
+	model := make(map[byte]byte)
+	old, oldModel := current[0], map[byte]byte{}
+	return runOps("PMap", data, func(op, arg byte) error {
+		key := arg % 64
+		if op%8 == 0 {
+			old, oldModel = current[0], maps.Clone(model)
+		}
+		for i, m := range current {
+			if op%2 == 0 {
+				current[i] = m.Set(key, op)
+			} else {
+				current[i] = m.Delete(key)
+			}
+		}
+		if op%2 == 0 {
+			model[key] = op
+		} else {
+			delete(model, key)
+		}
+		for _, m := range append(current, old) {
+			if err := m.Validate(); err != nil {
+				return err
+			}
+		}
+		for _, m := range current {
+			value, ok := m.Get(key)
+			if want, inModel := model[key]; m.Len() != len(model) || ok != inModel || value != want {
+				return errModelMismatch
+			}
+		}
+		// An old version is unaffected by later changes.
+		if old.Len() != len(oldModel) {
+			return errModelMismatch
+		}
+		mismatch := false
+		old.Range(func(k, v byte) bool {
+			if want, ok := oldModel[k]; !ok || v != want {
+				mismatch = true
+			}
+			return !mismatch
+		})
+		if mismatch {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkKeyedDisjointSetOps(data []byte) error {
+	ds := NewKeyedDisjointSet[string]()
+	model := make(map[string]string) // component label of each key
+	return runOps("KeyedDisjointSet", data, func(op, arg byte) error {
+		a, b := fmt.Sprint(op%32), fmt.Sprint(arg%32)
+		add := func(k string) {
+			if _, ok := model[k]; !ok {
+				model[k] = k
+			}
+		}
+		if op%4 == 0 {
+			ds.Add(a)
+			add(a)
+		} else {
+			add(a)
+			add(b)
+			if ds.Union(a, b) != (model[a] != model[b]) {
+				return errModelMismatch
+			}
+			from, to := model[b], model[a]
+			for k, label := range model {
+				if label == from {
+					model[k] = to
+				}
+			}
+		}
+		if err := ds.Validate(); err != nil {
+			return err
+		}
+		labels := make(map[string]bool)
+		for _, label := range model {
+			labels[label] = true
+		}
+		_, okA := model[a]
+		_, okB := model[b]
+		connected := okA && okB && model[a] == model[b]
+		if ds.Len() != len(model) || ds.Count() != len(labels) || ds.Connected(a, b) != connected {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+func checkGraphOps(data []byte) error {
+	graphs := []*Graph[byte]{NewDirectedGraph[byte](), NewUndirectedGraph[byte]()}
+	nodes := make(map[byte]bool)
+	edges := 0
+	return runOps("Graph", data, func(op, arg byte) error {
+		from, to := op%16, arg%16
+		if op >= 0xc0 {
+			for _, g := range graphs {
+				g.AddNode(to)
+			}
+			nodes[to] = true
+		} else {
+			for _, g := range graphs {
+				g.AddEdge(from, to, int(arg>>4))
+			}
+			nodes[from], nodes[to] = true, true
+			edges++
+		}
+		for _, g := range graphs {
+			if err := g.Validate(); err != nil {
+				return err
+			}
+			if len(g.Nodes()) != len(nodes) || len(g.Edges()) != edges {
+				return errModelMismatch
+			}
+		}
+		if op < 0xc0 && !(graphs[0].HasEdge(from, to) && graphs[1].HasEdge(to, from)) {
+			return errModelMismatch
+		}
+		return nil
+	})
+}
+
+// fuzzOps seeds f with a few fixed and random operation sequences and
+// fuzzes check, failing on the first error it returns.
+func fuzzOps(f *testing.F, check func([]byte) error) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 1, 0, 2, 0, 3, 1, 4, 2, 0, 3, 1})
+	f.Add([]byte{5, 5, 4, 4, 3, 3, 2, 2, 1, 1, 0, 0})
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 20; i++ {
+		data := make([]byte, rng.Intn(400))
+		rng.Read(data)
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := check(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzLinkedList(f *testing.F)       { fuzzOps(f, checkLinkedListOps) }
+func FuzzDoublyLinkedList(f *testing.F) { fuzzOps(f, checkDoublyLinkedListOps) }
+func FuzzBST(f *testing.F)              { fuzzOps(f, checkBSTOps) }
+func FuzzPriorityQueue(f *testing.F)    { fuzzOps(f, checkPriorityQueueOps) }
+func FuzzPQ(f *testing.F)               { fuzzOps(f, checkPQOps) }
+func FuzzTreeMap(f *testing.F)          { fuzzOps(f, checkTreeMapOps) }
+func FuzzIntervalTree(f *testing.F)     { fuzzOps(f, checkIntervalTreeOps) }
+func FuzzSkipList(f *testing.F)         { fuzzOps(f, checkSkipListOps) }
+func FuzzDeque(f *testing.F)            { fuzzOps(f, checkDequeOps) }
+func FuzzRingBuffer(f *testing.F)       { fuzzOps(f, checkRingBufferOps) }
+func FuzzDisjointSet(f *testing.F)      { fuzzOps(f, checkDisjointSetOps) }
+func FuzzDelayQueue(f *testing.F)       { fuzzOps(f, checkDelayQueueOps) }
+func FuzzFenwickTree(f *testing.F)      { fuzzOps(f, checkFenwickTreeOps) }
+func FuzzSegmentTree(f *testing.F)      { fuzzOps(f, checkSegmentTreeOps) }
+func FuzzTrie(f *testing.F)             { fuzzOps(f, checkTrieOps) }
+func FuzzPVector(f *testing.F)          { fuzzOps(f, checkPVectorOps) }
+func FuzzPMap(f *testing.F)             { fuzzOps(f, checkPMapOps) }
+func FuzzKeyedDisjointSet(f *testing.F) { fuzzOps(f, checkKeyedDisjointSetOps) }
+func FuzzGraph(f *testing.F)            { fuzzOps(f, checkGraphOps) }
+
+// TestPriorityQueueValidateBadIndex checks that Validate catches an item
+// whose Index was corrupted before Update, which makes heap.Fix repair
+// the wrong position.
+func TestPriorityQueueValidateBadIndex(t *testing.T) {
+	var pq PriorityQueue
+	for _, priority := range []int{1, 2, 3, 4, 5} {
+		heap.Push(&pq, &PriorityQueueItem{Value: priority, Priority: priority})
+	}
+	item := pq[4]
+	item.Index = 0
+	pq.Update(item, item.Value, 0)
+	if err := pq.Validate(); !errors.Is(err, ErrInvariant) {
+		t.Fatalf("Validate = %v, want ErrInvariant", err)
+	}
+}
+
+// TestValidateCorruption checks that each Validate catches a broken
+// invariant that the public API cannot produce.
+func TestValidateCorruption(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func() interface{ Validate() error }
+	}{
+		{"FenwickTree partial sum", func() interface{ Validate() error } {
+			ft := NewFenwickTreeFrom([]int{1, 2, 3, 4, 5})
+			ft.tree[4]++
+			return ft
+		}},
+		{"SegmentTree aggregate", func() interface{ Validate() error } {
+			st := NewSumSegmentTree([]int{1, 2, 3, 4, 5})
+			st.RangeUpdate(0, 3, 10)
+			st.tree[2]++
+			return st
+		}},
+		{"SegmentTree lost update", func() interface{ Validate() error } {
+			st := NewMinSegmentTree([]int{1, 2, 3, 4, 5})
+			st.RangeUpdate(0, 5, 10)
+			st.hasLazy[1] = false
+			return st
+		}},
+		{"Trie size", func() interface{ Validate() error } {
+			var tr Trie
+			tr.Insert("ab", 1)
+			tr.size++
+			return &tr
+		}},
+		{"Trie dead branch", func() interface{ Validate() error } {
+			var tr Trie
+			tr.Insert("ab", 1)
+			tr.root.children['a'].children['z'] = &trieNode{}
+			return &tr
+		}},
+		{"PVector short leaf", func() interface{ Validate() error } {
+			v := NewPVector(make([]int, 100)...)
+			leaf := v.root.children[1]
+			leaf.values = leaf.values[:31]
+			return v
+		}},
+		{"PVector tall root", func() interface{ Validate() error } {
+			v := NewPVector(make([]int, 100)...)
+			v.root, v.shift = &pvNode[int]{children: []*pvNode[int]{v.root}}, v.shift+pvBits
+			return v
+		}},
+		{"PMap length", func() interface{ Validate() error } {
+			m := NewPMap[int, int]().Set(1, 1).Set(2, 2)
+			m.length++
+			return m
+		}},
+		{"PMap misplaced key", func() interface{ Validate() error } {
+			m := NewPMapFunc[int, int](func(k int) uint64 { return uint64(k) }).Set(1, 1).Set(2, 2)
+			m.hash = func(k int) uint64 { return uint64(k) << 5 }
+			m.root.slots[0].entry.hash <<= 5
+			m.root.slots[1].entry.hash <<= 5
+			return m
+		}},
+		{"KeyedDisjointSet IDs", func() interface{ Validate() error } {
+			ds := NewKeyedDisjointSet[string]()
+			ds.Union("a", "b")
+			ds.ids["a"] = 1
+			return ds
+		}},
+		{"Graph one-sided edge", func() interface{ Validate() error } {
+			g := NewUndirectedGraph[string]()
+			g.AddEdge("a", "b", 1)
+			g.adj[1] = nil
+			return g
+		}},
+		{"Graph index", func() interface{ Validate() error } {
+			g := NewDirectedGraph[string]()
+			g.AddEdge("a", "b", 1)
+			g.index["b"] = 0
+			return g
+		}},
+	}
+	for _, tt := range tests {
+		if err := tt.corrupt().Validate(); !errors.Is(err, ErrInvariant) {
+			t.Errorf("%s: Validate = %v, want ErrInvariant", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package expr
+
+import (
+	"errors"
+	"math"
+
+	"github.com/abtin81badie/GoLangEssentials/mathutils"
+)
+
+// Func is a function callable from a formula.
+type Func func(args []float64) (float64, error)
+
+type function struct {
+	arity int // -1 for any number of arguments, at least one
+	fn    Func
+}
+
+// Env holds the variables and functions a formula can refer to.
+// Create one with NewEnv.
+type Env struct {
+	vars  map[string]float64
+	funcs map[string]function
+}
+
+// NewEnv returns an Env with the constants pi and e and the functions
+// sqrt, fact, abs, min and max.
+func NewEnv() *Env {
+	env := &Env{vars: map[string]float64{}, funcs: map[string]function{}}
+	env.Set("pi", math.Pi)
+	env.Set("e", math.E)
+	env.Register("sqrt", 1, func(args []float64) (float64, error) {
+		return mathutils.SquareRoot(args[0])
+	})
+	env.Register("fact", 1, factorial)
+	env.Register("abs", 1, func(args []float64) (float64, error) {
+		return mathutils.Abs(args[0]), nil
+	})
+	env.Register("min", -1, func(args []float64) (float64, error) {
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = mathutils.Min(result, arg)
+		}
+		return result, nil
+	})
+	env.Register("max", -1, func(args []float64) (float64, error) {
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = mathutils.Max(result, arg)
+		}
+		return result, nil
+	})
+	return env
+}
+
+// Set assigns value to the variable name.
+func (env *Env) Set(name string, value float64) {
+	env.vars[name] = value
+}
+
+// Register makes fn callable as name with arity arguments, or with any
+// number of at least one if arity is -1. It replaces any function already
+// registered as name.
+func (env *Env) Register(name string, arity int, fn Func) {
+	env.funcs[name] = function{arity: arity, fn: fn}
+}
+
+// factorial computes n! for a whole number n, as long as n! fits in an
+// int64.
+func factorial(args []float64) (float64, error) {
+	n := args[0]
+	if n < 0 || n != math.Trunc(n) {
+		return 0, errors.New("factorial needs a non-negative whole number")
+	}
+	if n >= 1<<63 {
+		return 0, &mathutils.OverflowError{Op: "Factorial", Operands: []any{n}}
+	}
+	result, err := mathutils.Factorial(int64(n))
+	return float64(result), err
+}
+
+// Eval evaluates node in env. A nil env is the same as NewEnv().
+func Eval(node Node, env *Env) (float64, error) {
+	if env == nil {
+		env = NewEnv()
+	}
+	return eval(node, env)
+}
+
+func eval(node Node, env *Env) (float64, error) {
+	switch n := node.(type) {
+	case *Number:
+		return n.Value, nil
+	case *Variable:
+		return env.lookup(n.Name, n.At)
+	case *Unary:
+		x, err := eval(n.X, env)
+		if err != nil {
+			return 0, err
+		}
+		return applyUnary(n.Op, x), nil
+	case *Binary:
+		x, err := eval(n.X, env)
+		if err != nil {
+			return 0, err
+		}
+		y, err := eval(n.Y, env)
+		if err != nil {
+			return 0, err
+		}
+		return applyBinary(n.Op, x, y, n.At)
+	case *Call:
+		args := make([]float64, len(n.Args))
+		for i, arg := range n.Args {
+			var err error
+			if args[i], err = eval(arg, env); err != nil {
+				return 0, err
+			}
+		}
+		return env.call(n.Name, args, n.At)
+	}
+	panic("expr: unknown node type")
+}
+
+// lookup returns the value of the variable name, used at column pos.
+func (env *Env) lookup(name string, pos int) (float64, error) {
+	value, ok := env.vars[name]
+	if !ok {
+		return 0, errorf(pos, ErrUndefined, "undefined variable %q", name)
+	}
+	return value, nil
+}
+
+// call calls the function name, used at column pos, with args.
+func (env *Env) call(name string, args []float64, pos int) (float64, error) {
+	f, ok := env.funcs[name]
+	if !ok {
+		return 0, errorf(pos, ErrUndefined, "undefined function %q", name)
+	}
+	if f.arity >= 0 && len(args) != f.arity {
+		return 0, errorf(pos, ErrArgCount, "%s takes %d argument(s), got %d", name, f.arity, len(args))
+	}
+	if f.arity < 0 && len(args) == 0 {
+		return 0, errorf(pos, ErrArgCount, "%s takes at least 1 argument", name)
+	}
+	result, err := f.fn(args)
+	if err != nil {
+		var overflow *mathutils.OverflowError
+		if errors.As(err, &overflow) {
+			return 0, errorf(pos, err, "%s: %v", name, err)
+		}
+		return 0, errorf(pos, errors.Join(ErrDomain, err), "%s: %v", name, err)
+	}
+	return result, nil
+}
+
+func applyUnary(op byte, x float64) float64 {
+	if op == '-' {
+		return -x
+	}
+	return x
+}
+
+// applyBinary applies op to x and y; pos is the column of op.
+func applyBinary(op byte, x, y float64, pos int) (float64, error) {
+	switch op {
+	case '+':
+		return mathutils.Add(x, y), nil
+	case '-':
+		return mathutils.Subtract(x, y), nil
+	case '*':
+		return mathutils.Multiply(x, y), nil
+	case '/':
+		result, err := mathutils.Divide(x, y)
+		if err != nil {
+			return 0, errorf(pos, err, "division by zero")
+		}
+		return result, nil
+	case '^':
+		return power(x, y, pos)
+	}
+	panic("expr: unknown operator " + string(op))
+}
+
+// power returns x ^ y. Whole exponents go through mathutils.Power, which
+// is exact for small integer results; others use math.Pow.
+func power(x, y float64, pos int) (float64, error) {
+	var result float64
+	if y == math.Trunc(y) && math.Abs(y) <= 1<<20 {
+		if x == 0 && y < 0 {
+			return 0, errorf(pos, mathutils.ErrDivisionByZero, "division by zero: 0 raised to a negative power")
+		}
+		result = mathutils.Power(x, int(y))
+	} else {
+		result = math.Pow(x, y)
+	}
+	if math.IsNaN(result) && !math.IsNaN(x) && !math.IsNaN(y) {
+		return 0, errorf(pos, ErrDomain, "%v ^ %v is not a real number", x, y)
+	}
+	return result, nil
+}
@@ -0,0 +1,59 @@
+// Package expr parses and evaluates arithmetic formulas such as
+// "3 + 4 * (2 ^ 3) - sqrt(16) / fact(3)".
+//
+// Parse turns a formula into an AST, which Eval walks directly or Compile
+// turns into a reverse Polish notation Program that runs on a stack. Both
+// evaluate with the same rules, backed by package mathutils. Formulas
+// may refer to variables and call functions, both looked up in an Env.
+//
+// Every error is an *Error carrying the 1-based column of the token at
+// fault, so a configuration loader can point at the mistake.
+package expr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors wrapped by *Error, for use with errors.Is. Arithmetic failures
+// wrap the mathutils error instead, such as mathutils.ErrDivisionByZero.
+var (
+	ErrSyntax    = errors.New("expr: syntax error")
+	ErrUndefined = errors.New("expr: undefined name")
+	ErrArgCount  = errors.New("expr: wrong number of arguments")
+	ErrDomain    = errors.New("expr: argument out of domain")
+)
+
+// ErrProgram is returned by Program.Eval for a program that was not made
+// by Compile and does not leave exactly one result.
+var ErrProgram = errors.New("expr: malformed program")
+
+// Error reports a problem with a formula at a column.
+type Error struct {
+	Pos int    // 1-based column of the offending token
+	Msg string // Description of the problem
+	Err error  // Underlying error, e.g. ErrSyntax
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("expr: column %d: %s", e.Pos, e.Msg)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// errorf returns an *Error at pos wrapping err.
+func errorf(pos int, err error, format string, args ...any) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...), Err: err}
+}
+
+// Evaluate parses src and evaluates it in env, which may be nil.
+func Evaluate(src string, env *Env) (float64, error) {
+	node, err := Parse(src)
+	if err != nil {
+		return 0, err
+	}
+	return Eval(node, env)
+}
@@ -0,0 +1,198 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/abtin81badie/GoLangEssentials/mathutils"
+)
+
+// testEnv returns an Env with variables x = 3 and café = 2, whose name
+// is longer in bytes than in columns, and a two-argument function
+// hyp(a, b).
+func testEnv() *Env {
+	env := NewEnv()
+	env.Set("x", 3)
+	env.Set("café", 2)
+	env.Register("hyp", 2, func(args []float64) (float64, error) {
+		return math.Hypot(args[0], args[1]), nil
+	})
+	return env
+}
+
+// evalBoth evaluates src by walking the AST and by running the compiled
+// program, and fails if the two disagree.
+func evalBoth(t *testing.T, src string) (float64, error) {
+	t.Helper()
+	node, err := Parse(src)
+	if err != nil {
+		return 0, err
+	}
+	env := testEnv()
+	tree, treeErr := Eval(node, env)
+	rpn, rpnErr := Compile(node).Eval(env)
+	if tree != rpn && !(math.IsNaN(tree) && math.IsNaN(rpn)) {
+		t.Fatalf("%q: Eval = %v, Program.Eval = %v", src, tree, rpn)
+	}
+	if (treeErr == nil) != (rpnErr == nil) || (treeErr != nil && treeErr.Error() != rpnErr.Error()) {
+		t.Fatalf("%q: Eval error %v, Program.Eval error %v", src, treeErr, rpnErr)
+	}
+	return tree, treeErr
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"3 + 4 * (2 ^ 3) - sqrt(16) / fact(3)", 3 + 32 - 4.0/6},
+		{"-2^2", -4},
+		{"(-2)^2", 4},
+		{"2^3^2", 512},
+		{"2*-3", -6},
+		{"2^-1", 0.5},
+		{"--3", 3},
+		{"+3", 3},
+		{"10 - 4 - 3", 3},
+		{"24 / 4 / 3", 2},
+		{"2 + 3 * 4", 14},
+		{"1e3 + .5 + 2.5E-1", 1000.75},
+		{"x * x + 1", 10},
+		{"café ^ x", 8},
+		{"hyp(x, 4)", 5},
+		{"max(1, x, 2) + min(5, 4)", 7},
+		{"abs(-2.5)", 2.5},
+		{"fact(0) + fact(5)", 121},
+		{"2 * pi", 2 * math.Pi},
+		{"e ^ 1", math.E},
+		{"0 ^ 0", 1},
+		{"4 ^ 0.5", 2},
+	}
+	for _, tt := range tests {
+		got, err := evalBoth(t, tt.src)
+		if err != nil || math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%q = %v, %v, want %v", tt.src, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseString(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"-2^2", "(-(2 ^ 2))"},
+		{"2^3^2", "(2 ^ (3 ^ 2))"},
+		{"2*-3", "(2 * (-3))"},
+		{"1 - 2 - 3", "((1 - 2) - 3)"},
+		{"f(1, g(), x)", "f(1, g(), x)"},
+	}
+	for _, tt := range tests {
+		node, err := Parse(tt.src)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.src, err)
+		}
+		if got := node.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestRPNString(t *testing.T) {
+	node, err := Parse("3 + 4 * (2 ^ 3) - sqrt(16) / -fact(3)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "3 4 2 3 ^ * + 16 sqrt/1 3 fact/1 neg / -"
+	if got := Compile(node).String(); got != want {
+		t.Fatalf("Compile = %s, want %s", got, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		pos  int
+		kind error
+	}{
+		// Syntax errors.
+		{"3 +", 4, ErrSyntax},
+		{"(1 + 2", 7, ErrSyntax},
+		{"1 + 2)", 6, ErrSyntax},
+		{"3 $ 4", 3, ErrSyntax},
+		{"2 3", 3, ErrSyntax},
+		{"f(1,)", 5, ErrSyntax},
+		{"1..2", 3, ErrSyntax},
+		// Columns count runes, not bytes.
+		{"é + ü $", 7, ErrSyntax},
+		{"«1»", 1, ErrSyntax},
+		// Undefined names.
+		{"y + 1", 1, ErrUndefined},
+		{"1 + nope(2)", 5, ErrUndefined},
+		{"π * 2", 1, ErrUndefined},
+		{"café + 1 + zz", 12, ErrUndefined},
+		// Wrong arity.
+		{"sqrt(1, 2)", 1, ErrArgCount},
+		{"2 * hyp(1)", 5, ErrArgCount},
+		{"max()", 1, ErrArgCount},
+		// Arithmetic.
+		{"1/0", 2, mathutils.ErrDivisionByZero},
+		{"café / (x - 3)", 6, mathutils.ErrDivisionByZero},
+		{"0 ^ -1", 3, mathutils.ErrDivisionByZero},
+		{"sqrt(-1)", 1, ErrDomain},
+		{"fact(2.5)", 1, ErrDomain},
+		{"fact(-1)", 1, ErrDomain},
+		{"(-8) ^ 0.5", 6, ErrDomain},
+		{"fact(25)", 1, mathutils.ErrOverflow},
+	}
+	for _, tt := range tests {
+		_, err := evalBoth(t, tt.src)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("%q: error %v is not an *Error", tt.src, err)
+			continue
+		}
+		if e.Pos != tt.pos {
+			t.Errorf("%q: error %q at column %d, want %d", tt.src, err, e.Pos, tt.pos)
+		}
+		if !errors.Is(err, tt.kind) {
+			t.Errorf("%q: error %q does not wrap %v", tt.src, err, tt.kind)
+		}
+	}
+}
+
+func TestProgramMalformed(t *testing.T) {
+	var zero Program
+	if _, err := zero.Eval(nil); !errors.Is(err, ErrProgram) {
+		t.Errorf("zero Program: Eval = %v, want ErrProgram", err)
+	}
+	bad := &Program{code: []instruction{{code: opPush, num: 1}, {code: opBinary, op: '+', pos: 3}}}
+	if _, err := bad.Eval(nil); !errors.Is(err, ErrProgram) {
+		t.Errorf("underflow: Eval = %v, want ErrProgram", err)
+	}
+	extra := &Program{code: []instruction{{code: opPush, num: 1}, {code: opPush, num: 2}}}
+	if _, err := extra.Eval(nil); !errors.Is(err, ErrProgram) {
+		t.Errorf("two results: Eval = %v, want ErrProgram", err)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	node, err := Parse("rate * hours + fee(hours)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	program := Compile(node)
+	env := NewEnv()
+	env.Register("fee", 1, func(args []float64) (float64, error) { return args[0] / 2, nil })
+	for _, tt := range []struct{ rate, hours, want float64 }{{10, 2, 21}, {0, 4, 2}, {1.5, 10, 20}} {
+		env.Set("rate", tt.rate)
+		env.Set("hours", tt.hours)
+		got, err := program.Eval(env)
+		if err != nil || got != tt.want {
+			t.Errorf("rate=%v hours=%v: %v, %v, want %v", tt.rate, tt.hours, got, err, tt.want)
+		}
+	}
+	// Registering again replaces the function.
+	env.Register("fee", 1, func([]float64) (float64, error) { return 0, errors.New("no fee today") })
+	if _, err := program.Eval(env); !errors.Is(err, ErrDomain) {
+		t.Errorf("failing function: %v, want ErrDomain", err)
+	}
+}
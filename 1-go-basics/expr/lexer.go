@@ -0,0 +1,108 @@
+package expr
+
+import (
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOp     // + - * / ^
+	tokLParen // (
+	tokRParen // )
+	tokComma  // ,
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64 // Value of a tokNumber
+	pos  int     // 1-based column of the first character
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of formula"
+	}
+	return strconv.Quote(t.text)
+}
+
+// tokenize splits src into tokens, ending with a tokEOF. Columns count
+// runes, not bytes, so they match what an editor shows.
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	col := 1
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		start, startCol := i, col
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r >= '0' && r <= '9' || r == '.':
+			i = scanNumber(src, i)
+			text := src[start:i]
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, errorf(startCol, ErrSyntax, "invalid number %q", text)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, num: num, pos: startCol})
+		case r == '_' || unicode.IsLetter(r):
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], pos: startCol})
+		default:
+			kind := tokOp
+			switch r {
+			case '+', '-', '*', '/', '^':
+			case '(':
+				kind = tokLParen
+			case ')':
+				kind = tokRParen
+			case ',':
+				kind = tokComma
+			default:
+				return nil, errorf(startCol, ErrSyntax, "unexpected character %q", r)
+			}
+			i += size
+			tokens = append(tokens, token{kind: kind, text: src[start:i], pos: startCol})
+		}
+		col += utf8.RuneCountInString(src[start:i])
+	}
+	return append(tokens, token{kind: tokEOF, pos: col}), nil
+}
+
+// scanNumber returns the end of the number starting at i: digits with an
+// optional fraction and exponent, like 12, 0.5, .5 or 1e-3.
+func scanNumber(src string, i int) int {
+	digits := func() {
+		for i < len(src) && src[i] >= '0' && src[i] <= '9' {
+			i++
+		}
+	}
+	digits()
+	if i < len(src) && src[i] == '.' {
+		i++
+		digits()
+	}
+	if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+		j := i + 1
+		if j < len(src) && (src[j] == '+' || src[j] == '-') {
+			j++
+		}
+		if j < len(src) && src[j] >= '0' && src[j] <= '9' {
+			i = j
+			digits()
+		}
+	}
+	return i
+}
@@ -0,0 +1,230 @@
+package expr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Node is a node of a parsed formula.
+type Node interface {
+	// Pos returns the 1-based column the node is reported at.
+	Pos() int
+	// String returns the node as a fully parenthesized formula.
+	String() string
+}
+
+// Number is a numeric literal.
+type Number struct {
+	Value float64
+	At    int
+}
+
+// Variable is a reference to a variable of the Env.
+type Variable struct {
+	Name string
+	At   int
+}
+
+// Unary is a sign applied to an operand: -X or +X.
+type Unary struct {
+	Op byte
+	X  Node
+	At int // Column of the operator
+}
+
+// Binary is X Op Y, where Op is one of + - * / ^.
+type Binary struct {
+	Op   byte
+	X, Y Node
+	At   int // Column of the operator
+}
+
+// Call is a function call such as sqrt(16).
+type Call struct {
+	Name string
+	Args []Node
+	At   int // Column of the function name
+}
+
+func (n *Number) Pos() int   { return n.At }
+func (n *Variable) Pos() int { return n.At }
+func (n *Unary) Pos() int    { return n.At }
+func (n *Binary) Pos() int   { return n.At }
+func (n *Call) Pos() int     { return n.At }
+
+func (n *Number) String() string   { return strconv.FormatFloat(n.Value, 'g', -1, 64) }
+func (n *Variable) String() string { return n.Name }
+func (n *Unary) String() string    { return "(" + string(n.Op) + n.X.String() + ")" }
+
+func (n *Binary) String() string {
+	return "(" + n.X.String() + " " + string(n.Op) + " " + n.Y.String() + ")"
+}
+
+func (n *Call) String() string {
+	args := make([]string, len(n.Args))
+	for i, arg := range n.Args {
+		args[i] = arg.String()
+	}
+	return n.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// Parse parses src into an AST. The usual precedence applies: ^ binds
+// tightest and is right-associative, then unary + and -, then * and /,
+// then + and -. So -2 ^ 2 is -4 and 2 ^ 3 ^ 2 is 512.
+func Parse(src string) (Node, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	node, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, errorf(t.pos, ErrSyntax, "unexpected %v", t)
+	}
+	return node, nil
+}
+
+// parser is a recursive-descent parser with one function per
+// precedence level.
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// isOp reports whether the next token is one of the operators in ops.
+func (p *parser) isOp(ops string) bool {
+	t := p.peek()
+	return t.kind == tokOp && strings.Contains(ops, t.text)
+}
+
+// expression := term (("+" | "-") term)*
+func (p *parser) expression() (Node, error) {
+	return p.binaryLevel("+-", p.term)
+}
+
+// term := unary (("*" | "/") unary)*
+func (p *parser) term() (Node, error) {
+	return p.binaryLevel("*/", p.unary)
+}
+
+// binaryLevel parses a left-associative chain of operand separated by ops.
+func (p *parser) binaryLevel(ops string, operand func() (Node, error)) (Node, error) {
+	x, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp(ops) {
+		op := p.next()
+		y, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{Op: op.text[0], X: x, Y: y, At: op.pos}
+	}
+	return x, nil
+}
+
+// unary := ("+" | "-") unary | power
+func (p *parser) unary() (Node, error) {
+	if p.isOp("+-") {
+		op := p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Op: op.text[0], X: x, At: op.pos}, nil
+	}
+	return p.power()
+}
+
+// power := primary ("^" unary)?
+// The exponent is parsed as a unary, which makes ^ right-associative
+// and allows 2 ^ -1.
+func (p *parser) power() (Node, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp("^") {
+		return x, nil
+	}
+	op := p.next()
+	y, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return &Binary{Op: '^', X: x, Y: y, At: op.pos}, nil
+}
+
+// primary := number | name | name "(" [expression ("," expression)*] ")" | "(" expression ")"
+func (p *parser) primary() (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return &Number{Value: t.num, At: t.pos}, nil
+	case tokIdent:
+		if p.peek().kind != tokLParen {
+			return &Variable{Name: t.text, At: t.pos}, nil
+		}
+		p.next()
+		return p.call(t)
+	case tokLParen:
+		x, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokRParen, t); err != nil {
+			return nil, err
+		}
+		return x, nil
+	}
+	return nil, errorf(t.pos, ErrSyntax, "expected a number, name or \"(\", found %v", t)
+}
+
+// call parses the arguments of a call to name, after its "(".
+func (p *parser) call(name token) (Node, error) {
+	call := &Call{Name: name.text, At: name.pos}
+	if p.peek().kind == tokRParen {
+		p.next()
+		return call, nil
+	}
+	for {
+		arg, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+		if p.peek().kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect(tokRParen, name); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+// expect consumes a token of the given kind, or reports what was found
+// instead. opener is the token whose closing ")" is missing.
+func (p *parser) expect(kind tokenKind, opener token) error {
+	if t := p.next(); t.kind != kind {
+		return errorf(t.pos, ErrSyntax, "expected \")\" to close %v at column %d, found %v", opener, opener.pos, t)
+	}
+	return nil
+}
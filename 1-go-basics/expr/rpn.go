@@ -0,0 +1,150 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/abtin81badie/GoLangEssentials/datastructures"
+)
+
+type opcode int
+
+const (
+	opPush   opcode = iota // push num
+	opLoad                 // push the variable name
+	opUnary                // apply op to the top value
+	opBinary               // apply op to the top two values
+	opCall                 // call name with the top argc values
+)
+
+type instruction struct {
+	code opcode
+	num  float64
+	name string
+	op   byte
+	argc int
+	pos  int // column reported in errors
+}
+
+func (in instruction) String() string {
+	switch in.code {
+	case opPush:
+		return strconv.FormatFloat(in.num, 'g', -1, 64)
+	case opLoad:
+		return in.name
+	case opUnary:
+		if in.op == '-' {
+			return "neg"
+		}
+		return "pos"
+	case opBinary:
+		return string(in.op)
+	}
+	return fmt.Sprintf("%s/%d", in.name, in.argc)
+}
+
+// Program is a formula compiled to reverse Polish notation, ready to be
+// evaluated many times, for example with different variables.
+type Program struct {
+	code []instruction
+}
+
+// Compile turns node into a Program.
+func Compile(node Node) *Program {
+	p := &Program{}
+	p.emit(node)
+	return p
+}
+
+// emit appends the instructions for node in postfix order.
+func (p *Program) emit(node Node) {
+	switch n := node.(type) {
+	case *Number:
+		p.code = append(p.code, instruction{code: opPush, num: n.Value, pos: n.At})
+	case *Variable:
+		p.code = append(p.code, instruction{code: opLoad, name: n.Name, pos: n.At})
+	case *Unary:
+		p.emit(n.X)
+		p.code = append(p.code, instruction{code: opUnary, op: n.Op, pos: n.At})
+	case *Binary:
+		p.emit(n.X)
+		p.emit(n.Y)
+		p.code = append(p.code, instruction{code: opBinary, op: n.Op, pos: n.At})
+	case *Call:
+		for _, arg := range n.Args {
+			p.emit(arg)
+		}
+		p.code = append(p.code, instruction{code: opCall, name: n.Name, argc: len(n.Args), pos: n.At})
+	default:
+		panic("expr: unknown node type")
+	}
+}
+
+// String returns the program in reverse Polish notation, with unary
+// minus as "neg" and calls as name/argc, like "2 3 ^ sqrt/1".
+func (p *Program) String() string {
+	parts := make([]string, len(p.code))
+	for i, in := range p.code {
+		parts[i] = in.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// Eval runs the program on a stack in env. A nil env is the same as
+// NewEnv(). Results and errors match Eval on the source AST. A Program
+// not made by Compile, such as the zero value, may be malformed; Eval
+// then returns an error wrapping ErrProgram.
+func (p *Program) Eval(env *Env) (float64, error) {
+	if env == nil {
+		env = NewEnv()
+	}
+	stack := &datastructures.Stack[float64]{}
+	// pop removes n values and returns them in push order.
+	pop := func(n int, pos int) ([]float64, error) {
+		if stack.Len() < n {
+			return nil, fmt.Errorf("%w: column %d: stack underflow", ErrProgram, pos)
+		}
+		values := make([]float64, n)
+		for i := n - 1; i >= 0; i-- {
+			values[i], _ = stack.Pop()
+		}
+		return values, nil
+	}
+	for _, in := range p.code {
+		var result float64
+		var err error
+		switch in.code {
+		case opPush:
+			result = in.num
+		case opLoad:
+			result, err = env.lookup(in.name, in.pos)
+		case opUnary:
+			var x []float64
+			if x, err = pop(1, in.pos); err == nil {
+				result = applyUnary(in.op, x[0])
+			}
+		case opBinary:
+			var xy []float64
+			if xy, err = pop(2, in.pos); err == nil {
+				result, err = applyBinary(in.op, xy[0], xy[1], in.pos)
+			}
+		case opCall:
+			var args []float64
+			if args, err = pop(in.argc, in.pos); err == nil {
+				result, err = env.call(in.name, args, in.pos)
+			}
+		default:
+			err = fmt.Errorf("%w: unknown opcode %d", ErrProgram, in.code)
+		}
+		if err != nil {
+			return 0, err
+		}
+		stack.Push(result)
+	}
+	if stack.Len() != 1 {
+		return 0, fmt.Errorf("%w: %d values left on the stack, want 1", ErrProgram, stack.Len())
+	}
+	result, _ := stack.Pop()
+	return result, nil
+}
@@ -2,17 +2,30 @@ package main
 
 import (
 	"bufio"
+	"cmp"
 	"container/heap"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/abtin81badie/GoLangEssentials/alias"
+	"github.com/abtin81badie/GoLangEssentials/btree"
+	"github.com/abtin81badie/GoLangEssentials/cache"
 	"github.com/abtin81badie/GoLangEssentials/datastructures"
+	"github.com/abtin81badie/GoLangEssentials/expr"
 	"github.com/abtin81badie/GoLangEssentials/greeting"
 	"github.com/abtin81badie/GoLangEssentials/mathutils"
+	"github.com/abtin81badie/GoLangEssentials/sketch"
+	"github.com/abtin81badie/GoLangEssentials/sorting"
 	"github.com/abtin81badie/GoLangEssentials/stringutils"
+	"github.com/abtin81badie/GoLangEssentials/visualize"
 )
 
 /*
@@ -336,6 +349,29 @@ func main() {
 	// Using the mathutils package
 	fmt.Println("Addition:", mathutils.Add(10, 5))
 	fmt.Println("Multiplication:", mathutils.Multiply(4, 3))
+	var fileSize int64 = 3 << 30
+	var chunk uint32 = 1 << 20
+	var ratio float32 = 0.75
+	// Expected: 3221225473 1048576 0.5
+	fmt.Println("Generic math:", mathutils.Add(fileSize, 1), mathutils.Max(chunk, 4096), mathutils.Clamp(ratio, 0, 0.5))
+	// Expected: 4052555153018976267 0.125
+	fmt.Println("Power by squaring:", mathutils.Power(int64(3), 39), mathutils.Power(2.0, -3))
+	truncQ, truncR, _ := mathutils.DivMod(-7, 2, mathutils.Truncated)
+	floorQ, floorR, _ := mathutils.DivMod(-7, 2, mathutils.Floored)
+	euclidQ, euclidR, _ := mathutils.DivMod(-7, -2, mathutils.Euclidean)
+	// Expected: -3 -1 | -4 1 | 4 1
+	fmt.Println("-7/2 truncated, floored; -7/-2 Euclidean:", truncQ, truncR, "|", floorQ, floorR, "|", euclidQ, euclidR)
+	if _, err := mathutils.CheckedMultiply(math.MaxInt, 2); errors.Is(err, mathutils.ErrOverflow) {
+		fmt.Println("Checked multiply:", err) // Expected: mathutils: Multiply(9223372036854775807, 2) overflows int
+	}
+	fmt.Println("Saturating add:", mathutils.SaturatingAdd(math.MaxInt, 1) == math.MaxInt) // Expected: true
+	if _, err := mathutils.Factorial(21); err != nil {
+		fmt.Println("Factorial(21):", err) // Expected: mathutils: Factorial(21) overflows int
+	}
+	bigFact, _ := mathutils.BigFactorial(21)
+	fmt.Println("BigFactorial(21):", bigFact) // Expected: 51090942171709440000
+	bigPow, _ := mathutils.BigPower(big.NewInt(2), 100)
+	fmt.Println("BigPower(2, 100):", bigPow) // Expected: 1267650600228229401496703205376
 
 	// Using alias package
 	dateStr := alias.MyCustomString("2024-02-07")
@@ -404,14 +440,41 @@ func main() {
 
 	// Using datastructures package
 	// ----- Linked List Example -----
-	ll := datastructures.LinkedList{}
+	ll := datastructures.LinkedList[string]{}
 	ll.Append("first")
 	ll.Append("second")
 	ll.Prepend("zero")
-	ll.Print() // Expected: zero -> first -> second -> nil
+	fmt.Println(ll.String()) // Expected: zero -> first -> second -> nil
+	datastructures.Delete(&ll, "first")
+	fmt.Println(ll.String()) // Expected: zero -> second -> nil
+	ll.InsertAt(1, "one")
+	ll.Reverse()
+	fmt.Println(ll.String(), "len", ll.Len()) // Expected: second -> one -> zero -> nil len 3
+
+	odds, evens := &datastructures.LinkedList[int]{}, &datastructures.LinkedList[int]{}
+	for i := 1; i <= 6; i++ {
+		if i%2 == 1 {
+			odds.Append(i)
+		} else {
+			evens.Append(i)
+		}
+	}
+	merged := datastructures.MergeSorted(odds, evens)
+	upper := merged.SplitAt(3)
+	fmt.Println("Merged and split:", merged, "|", upper) // Expected: 1 -> 2 -> 3 -> nil | 4 -> 5 -> 6 -> nil
+	fmt.Println("Has cycle:", merged.HasCycle())         // Expected: false
+
+	// ----- Doubly Linked List Example -----
+	var recent datastructures.DoublyLinkedList[string]
+	recent.PushBack("home")
+	docs := recent.PushBack("docs")
+	recent.PushBack("blog")
+	// Move through the node handle in O(1), no search needed.
+	recent.MoveToFront(docs)
+	fmt.Println("Doubly linked:", recent.String()) // Expected: nil <-> docs <-> home <-> blog <-> nil
 
 	// ----- Stack Example -----
-	var s datastructures.Stack
+	var s datastructures.Stack[int]
 	s.Push(10)
 	s.Push(20)
 	top, _ := s.Peek()
@@ -420,7 +483,7 @@ func main() {
 	fmt.Println("Popped:", item) // Expected: 20
 
 	// ----- Queue Example -----
-	var q datastructures.Queue
+	var q datastructures.Queue[int]
 	q.Enqueue(100)
 	q.Enqueue(200)
 	front, _ := q.Peek()
@@ -428,6 +491,15 @@ func main() {
 	item, _ = q.Dequeue()
 	fmt.Println("Dequeued:", item) // Expected: 100
 
+	// ----- Deque Example -----
+	var dq datastructures.Deque[int]
+	dq.PushBack(2)
+	dq.PushBack(3)
+	dq.PushFront(1)
+	fmt.Println("Deque at index 1:", dq.At(1)) // Expected: 2
+	back, _ := dq.PopBack()
+	fmt.Println("Deque popped back:", back) // Expected: 3
+
 	// ----- Binary Search Tree Example -----
 	bst := datastructures.BinarySearchTree{}
 	bst.Insert(50)
@@ -439,6 +511,17 @@ func main() {
 	fmt.Println("Search 40 in BST:", bst.Search(40))
 	fmt.Println("Search 90 in BST:", bst.Search(90))
 
+	// ----- TreeMap (balanced ordered map) Example -----
+	var tm datastructures.TreeMap[int, string]
+	for i, name := range []string{"a", "b", "c", "d", "e"} {
+		tm.Put((i+1)*10, name) // Sorted input stays balanced
+	}
+	tm.Delete(30)
+	fmt.Println("TreeMap keys:", tm.InOrderTraversal()) // Expected: [10 20 40 50]
+	floorKey, _, _ := tm.Floor(35)
+	fmt.Println("TreeMap floor of 35:", floorKey)   // Expected: 20
+	fmt.Println("TreeMap rank of 40:", tm.Rank(40)) // Expected: 2
+
 	// ----- Priority Queue Example -----
 	items := []*datastructures.PriorityQueueItem{
 		{Value: "Task1", Priority: 3},
@@ -455,16 +538,219 @@ func main() {
 	itemPopped := heap.Pop(&pq).(*datastructures.PriorityQueueItem)
 	fmt.Println("Highest Priority Task:", itemPopped.Value) // Expected: Task4
 
+	// ----- JSON Round Trip Example -----
+	pqJSON, _ := json.Marshal(pq)
+	fmt.Println("Priority queue as JSON:", string(pqJSON)) // Expected: heap order, Task2 first
+	var restoredPQ datastructures.PriorityQueue
+	if err := json.Unmarshal(pqJSON, &restoredPQ); err == nil {
+		fmt.Println("Restored next task:", heap.Pop(&restoredPQ).(*datastructures.PriorityQueueItem).Value) // Expected: Task2
+	}
+	var badTree datastructures.BinarySearchTree
+	fmt.Println(json.Unmarshal([]byte(`[5, 3, 7, 4]`), &badTree)) // Expected: error, 4 must be >= 5 once 7 is in the right subtree
+
+	// ----- Generic PQ Example (no container/heap calls needed) -----
+	tasks := datastructures.NewMinPQ[string]()
+	tasks.Push("Backup", 2)
+	report := tasks.Push("Report", 3)
+	tasks.Push("Deploy", 2) // Same priority as Backup, pops after it
+	tasks.Update(report, 1)
+	for !tasks.IsEmpty() {
+		task, priority, _ := tasks.Pop()
+		fmt.Println("PQ popped:", task, "priority", priority) // Expected: Report, Backup, Deploy
+	}
+
+	// ----- Graph Example -----
+	deps := datastructures.NewDirectedGraph[string]()
+	deps.AddEdge("build", "test", 5)
+	deps.AddEdge("build", "lint", 1)
+	deps.AddEdge("lint", "test", 2)
+	deps.AddEdge("test", "deploy", 3)
+	order, _ := deps.TopologicalSort()
+	fmt.Println("Topological order:", order) // Expected: [build lint test deploy]
+	paths, _ := deps.Dijkstra("build")
+	route, cost, _ := paths.PathTo("deploy")
+	fmt.Println("Shortest path build->deploy:", route, "cost", cost) // Expected: [build lint test deploy] cost 6
+
+	// ----- Trie (autocomplete) Example -----
+	var commands datastructures.Trie
+	commands.Insert("open", 5)
+	commands.Insert("open file", 9)
+	commands.Insert("open folder", 7)
+	// Non-ASCII words are split by rune, not byte.
+	commands.Insert("öffnen", 3)
+	fmt.Println("Words with prefix 'open f':", commands.WithPrefix("open f")) // Expected: [open file open folder]
+	fmt.Println("Top 2 for 'op':", commands.TopK("op", 2))                    // Expected: [{open file 9} {open folder 7}]
+	longest, _ := commands.LongestPrefixOf("open files now")
+	fmt.Println("Longest command prefix:", longest) // Expected: open file
+
+	// ----- Skip List (leaderboard) Example -----
+	// Keys are (score, player) so players with equal scores can coexist.
+	type scoreKey struct {
+		Score  int
+		Player string
+	}
+	board := datastructures.NewSkipList[scoreKey, struct{}](func(a, b scoreKey) int {
+		return cmp.Or(cmp.Compare(a.Score, b.Score), strings.Compare(a.Player, b.Player))
+	}, 1)
+	board.Set(scoreKey{120, "ana"}, struct{}{})
+	board.Set(scoreKey{300, "bob"}, struct{}{})
+	board.Set(scoreKey{200, "cyd"}, struct{}{})
+	fmt.Println("Leaderboard top 2:", board.TopN(2)) // Expected: bob (300), cyd (200)
+	anaRank, _ := board.RevRank(scoreKey{120, "ana"})
+	fmt.Println("Rank of ana:", anaRank+1) // Expected: 3
+
+	// ----- Disjoint Set (Union-Find) Example -----
+	clusters := datastructures.NewKeyedDisjointSet[string]()
+	clusters.Union("db-1", "db-2")
+	clusters.Union("web-1", "web-2")
+	clusters.Union("db-2", "cache-1")
+	fmt.Println("db-1 connected to cache-1:", clusters.Connected("db-1", "cache-1")) // Expected: true
+	fmt.Println("Clusters:", clusters.Count(), clusters.Components())                // Expected: 2 [[db-1 db-2 cache-1] [web-1 web-2]]
+
+	// ----- Range Query Trees Example -----
+	requestsPerMinute := []int{12, 7, 30, 4, 18, 25}
+	fenwick := datastructures.NewFenwickTreeFrom(requestsPerMinute)
+	fenwick.Add(3, 6)
+	fmt.Println("Fenwick sum of minutes [1, 4):", fenwick.RangeSum(1, 4)) // Expected: 47
+	peaks := datastructures.NewMaxSegmentTree(requestsPerMinute)
+	peaks.RangeUpdate(0, 3, 10)                                   // Add 10 to the first three minutes
+	fmt.Println("Segment tree max of [0, 4):", peaks.Query(0, 4)) // Expected: 40
+
+	// ----- Disk-backed B+Tree Example -----
+	if err := demoBTree(); err != nil {
+		fmt.Println("B+tree demo failed:", err)
+	}
+
+	// ----- Persistent Collections Example -----
+	configV1 := datastructures.NewPMap[string, int]().Set("workers", 4).Set("retries", 3)
+	configV2 := configV1.Set("workers", 8) // configV1 is untouched and safe to share
+	oldWorkers, _ := configV1.Get("workers")
+	newWorkers, _ := configV2.Get("workers")
+	fmt.Println("PMap workers v1/v2:", oldWorkers, newWorkers) // Expected: 4 8
+	history := datastructures.NewPVector("draft", "review")
+	published := history.Append("published")
+	fmt.Println("PVector versions:", history.Slice(), published.Slice()) // Expected: [draft review] [draft review published]
+
+	// ----- Probabilistic Sketches Example -----
+	seen := sketch.NewBloomFilter(1000, 0.01)
+	hits := sketch.NewCountMinSketch(0.01, 0.01)
+	visitors := sketch.NewHyperLogLog(12)
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.1", "10.0.0.3", "10.0.0.1"} {
+		seen.AddString(ip)
+		hits.AddString(ip, 1)
+		visitors.AddString(ip)
+	}
+	known, unknown := seen.ContainsString("10.0.0.2"), seen.ContainsString("10.9.9.9")
+	fmt.Println("Bloom seen 10.0.0.2 / 10.9.9.9:", known, unknown)               // Expected: true false
+	fmt.Println("Count-Min hits for 10.0.0.1:", hits.EstimateString("10.0.0.1")) // Expected: 3
+	fmt.Println("HyperLogLog distinct visitors:", visitors.Count())              // Expected: 3
+	encoded, _ := visitors.MarshalBinary()
+	var restoredVisitors sketch.HyperLogLog
+	if err := restoredVisitors.UnmarshalBinary(encoded); err == nil {
+		fmt.Println("Restored HyperLogLog:", restoredVisitors.Count(), "from", len(encoded), "bytes") // Expected: 3 from 4099 bytes
+	}
+
+	// ----- Interval Tree Example -----
+	var bookings datastructures.IntervalTree[int, string]
+	bookings.Insert(9, 11, "standup")   // 09:00-11:00
+	bookings.Insert(13, 14, "lunch")    // 13:00-14:00
+	bookings.Insert(10, 12, "review")   // 10:00-12:00
+	bookings.Insert(14, 16, "planning") // Touches lunch, no overlap
+	if conflict, ok := bookings.FirstOverlap(11, 13); ok {
+		// Expected: Booking [11, 13) conflicts with review [10, 12)
+		fmt.Println("Booking [11, 13) conflicts with", conflict.Value, conflict.Interval)
+	}
+	fmt.Println("Meetings at 10:", len(bookings.QueryPoint(10))) // Expected: 2
+	fmt.Println("Busy hours:", bookings.Coverage())              // Expected: [[9, 12) [13, 16)]
+
+	// ----- Delay Queue Example -----
+	scheduled := datastructures.NewDelayQueue[string](nil)
+	scheduled.PushAfter("retry upload", 20*time.Millisecond)
+	reminder := scheduled.PushAfter("send reminder", 10*time.Millisecond)
+	scheduled.PushAfter("rebuild index", 5*time.Millisecond)
+	scheduled.Cancel(reminder)
+	_, due := scheduled.Poll()
+	fmt.Println("Any job due yet:", due) // Expected: false
+	takeCtx, cancelTake := context.WithTimeout(context.Background(), time.Second)
+	for scheduled.Len() > 0 {
+		job, err := scheduled.Take(takeCtx)
+		if err != nil {
+			fmt.Println("Take failed:", err)
+			break
+		}
+		fmt.Println("Running scheduled job:", job) // Expected: rebuild index, then retry upload
+	}
+	cancelTake()
+
+	// ----- Visualization Example -----
+	searchPath, _ := bst.SearchPath(40)
+	visited := visualize.Path(searchPath)
+	// Prints the BST sideways with the search path 50, 30, 40 in *asterisks*
+	fmt.Print(visualize.BSTText(&bst, &visualize.Options{Highlight: visited}))
+	// Paste into `dot -Tsvg` to draw the same tree with the path highlighted
+	bstDOT := visualize.BSTDOT(&bst, &visualize.Options{Highlight: visited})
+	fmt.Println("Highlighted DOT edges:", strings.Count(bstDOT, "penwidth")) // Expected: 2
+
+	// ----- Expression Evaluation Example -----
+	formula, err := expr.Parse("3 + 4 * (2 ^ 3) - sqrt(16) / fact(3)")
+	if err != nil {
+		fmt.Println("Error:", err)
+	}
+	// Expected: ((3 + (4 * (2 ^ 3))) - (sqrt(16) / fact(3)))
+	fmt.Println("Parsed:", formula)
+	// Expected: 3 4 2 3 ^ * + 16 sqrt/1 3 fact/1 / -
+	fmt.Println("RPN:", expr.Compile(formula))
+	formulaValue, _ := expr.Eval(formula, nil)
+	fmt.Printf("Value: %.4f\n", formulaValue) // Expected: 34.3333
+
+	env := expr.NewEnv()
+	env.Set("price", 80)
+	env.Register("discount", 2, func(args []float64) (float64, error) {
+		return args[0] * (1 - args[1]/100), nil
+	})
+	checkout, err := expr.Parse("discount(price, 25) + 5")
+	if err != nil {
+		fmt.Println("Error:", err)
+	}
+	checkoutTotal, _ := expr.Compile(checkout).Eval(env)
+	fmt.Println("Checkout total:", checkoutTotal) // Expected: 65
+	_, err = expr.Evaluate("10 / (price - 80)", env)
+	// Expected: expr: column 4: division by zero true
+	fmt.Println(err, errors.Is(err, mathutils.ErrDivisionByZero))
+
 	// ----- Binary Search Algorithm Example -----
 	sortedArr := []int{1, 3, 5, 7, 9}
 	index := datastructures.BinarySearch(sortedArr, 7)
 	fmt.Println("Index of 7:", index) // Expected: 3
 
+	// ----- Bound Searches over Sorted Timestamps -----
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var stamps []time.Time
+	for _, minutes := range []int{0, 5, 5, 10, 20, 30} {
+		stamps = append(stamps, base.Add(time.Duration(minutes)*time.Minute))
+	}
+	byTime := func(a, b time.Time) int { return a.Compare(b) }
+	from := datastructures.LowerBoundFunc(stamps, base.Add(5*time.Minute), byTime)
+	to := datastructures.UpperBoundFunc(stamps, base.Add(20*time.Minute), byTime)
+	fmt.Println("Events in [00:05, 00:20]:", to-from) // Expected: 4
+
+	// ----- Rotated Sorted Slice Search -----
+	fmt.Println("Index of 5 in rotated slice:", datastructures.SearchRotated([]int{4, 5, 6, 1, 2, 3}, 5)) // Expected: 1
+
 	// ----- Standard Package Demonstrations -----
 	datastructures.DemoContainerList()
 	datastructures.DemoContainerHeap()
-	datastructures.DemoContainerRing()
+	datastructures.DemoRingBuffer()
 	datastructures.DemoSort()
+
+	// ----- Instrumented Sorting Example -----
+	samples := []int{42, 7, 19, 7, 88, 3, 56, 19, 1, 64, 25, 7, 90, 12}
+	for _, result := range sorting.Compare(samples) {
+		fmt.Printf("sorting.%s: %v\n", result.Algorithm, result.Stats)
+	}
+	radixSorted := append([]int(nil), samples...)
+	sorting.RadixSortLSD(radixSorted)
+	fmt.Println("Radix sorted:", radixSorted)
 	datastructures.DemoMathRand()
 
 	// exampleFunction is a simple function that prints a message.
@@ -1281,6 +1567,55 @@ Error Handling
 --------------
 Functions in Go can return multiple values, and error handling is done explicitly.
 */
+// demoBTree stores a few entries in an on-disk B+tree, reopens the file
+// and shows that they survived.
+func demoBTree() error {
+	dir, err := os.MkdirTemp("", "btree-demo")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "inventory.db")
+
+	db, err := btree.Open(path, nil)
+	if err != nil {
+		return err
+	}
+	err = db.Update(func(tx *btree.Tx) error {
+		for _, item := range []string{"apple:12", "banana:5", "cherry:40", "date:7"} {
+			name, count, _ := strings.Cut(item, ":")
+			if err := tx.Put([]byte(name), []byte(count)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := db.Close(); err != nil {
+		return err
+	}
+
+	db, err = btree.Open(path, nil)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	count, found, err := db.Get([]byte("cherry"))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("B+tree cherry after reopen: %s %v\n", count, found) // Expected: 40 true
+	fmt.Print("B+tree scan [banana, date):")
+	err = db.Scan([]byte("banana"), []byte("date"), func(key, value []byte) bool {
+		fmt.Printf(" %s=%s", key, value)
+		return true
+	})
+	fmt.Println() // Expected: banana=5 cherry=40
+	return err
+}
+
 func demoErrorHandling(n int) error {
 	if n%2 != 0 {
 		return fmt.Errorf("number is odd")
@@ -1507,6 +1842,7 @@ func makeMultiplier(factor int) func(int) int {
 }
 
 // makeCacheChecker returns a closure that caches function results to avoid redundant calculations.
+// The cache grows without bound; see cache.Memoize for a bounded, goroutine-safe version.
 func makeCacheChecker(fn func(int) int) func(int) int {
 	cache := make(map[int]int) // Cache storage
 
@@ -1559,6 +1895,23 @@ func demoClosure() {
 	fmt.Println("Compute 4 x 2 again:", multiplierWithCache(4)) // Cache hit, returns 8
 	fmt.Println("Compute 5 x 2 again:", multiplierWithCache(5)) // Cache hit, returns 10
 
+	// Example: Bounded memoization with the cache package
+	fmt.Println("=== cache.Memoize Demo ===")
+	lru := cache.New(cache.LRU, cache.Options[int, int]{
+		Capacity:     2,
+		TTL:          time.Minute,
+		Synchronized: true,
+		OnEvict: func(key, value int, reason cache.EvictReason) {
+			fmt.Println("Evicted", key, "->", value, "reason:", reason)
+		},
+	})
+	memoDouble := cache.Memoize(double, lru)
+	memoDouble(4)
+	memoDouble(5)
+	memoDouble(4)
+	memoDouble(6)                            // Evicts 5, the least recently used
+	fmt.Println("Cache stats:", lru.Stats()) // Expected: {1 3 1 0}
+
 	// Example: getSequence Closure
 	fmt.Println("==== getSequence Demo ===")
 	sequence1 := getSequence(100) // Starts from 100
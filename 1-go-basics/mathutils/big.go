@@ -0,0 +1,28 @@
+package mathutils
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// BigFactorial returns n! exactly, however large. It returns an error if
+// n is negative.
+func BigFactorial(n int) (*big.Int, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("error: factorial is not defined for negative numbers")
+	}
+	return new(big.Int).MulRange(1, int64(n)), nil
+}
+
+// BigPower returns base raised to exp exactly, however large. It returns
+// an error if base is nil, or if exp is negative, since the result would
+// not be an integer.
+func BigPower(base *big.Int, exp int) (*big.Int, error) {
+	if base == nil {
+		return nil, fmt.Errorf("mathutils: nil base")
+	}
+	if exp < 0 {
+		return nil, fmt.Errorf("mathutils: negative exponent %d", exp)
+	}
+	return new(big.Int).Exp(base, big.NewInt(int64(exp)), nil), nil
+}
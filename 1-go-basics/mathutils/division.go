@@ -0,0 +1,65 @@
+package mathutils
+
+import "fmt"
+
+// DivisionMode selects how DivMod rounds a quotient that is not exact.
+// The modes differ only when an operand is negative; in every mode
+// a == quotient*b + remainder.
+type DivisionMode int
+
+const (
+	// Truncated rounds toward zero, like Go's / and % operators. The
+	// remainder has the sign of a: -7 / 2 is -3 remainder -1.
+	Truncated DivisionMode = iota
+	// Floored rounds toward negative infinity. The remainder has the sign
+	// of b: -7 / 2 is -4 remainder 1, and 7 / -2 is -4 remainder -1.
+	Floored
+	// Euclidean keeps the remainder in [0, |b|): -7 / 2 is -4 remainder 1,
+	// and -7 / -2 is 4 remainder 1.
+	Euclidean
+)
+
+func (m DivisionMode) String() string {
+	switch m {
+	case Truncated:
+		return "Truncated"
+	case Floored:
+		return "Floored"
+	case Euclidean:
+		return "Euclidean"
+	}
+	return fmt.Sprintf("DivisionMode(%d)", int(m))
+}
+
+// DivMod returns the quotient and remainder of a / b, rounded as mode
+// says. Returns ErrDivisionByZero if b is zero, and an *OverflowError for
+// the most negative signed integer divided by -1. For unsigned types all
+// modes agree. It panics if mode is unknown.
+func DivMod[T Integer](a, b T, mode DivisionMode) (quotient, remainder T, err error) {
+	q, err := Divide(a, b)
+	if err != nil {
+		return 0, 0, err
+	}
+	r := a % b
+	switch mode {
+	case Truncated:
+	case Floored:
+		if r != 0 && (r < 0) != (b < 0) {
+			q--
+			r += b
+		}
+	case Euclidean:
+		if r < 0 {
+			if b > 0 {
+				q--
+				r += b
+			} else {
+				q++
+				r -= b
+			}
+		}
+	default:
+		panic(fmt.Sprintf("mathutils: unknown %v", mode))
+	}
+	return q, r, nil
+}
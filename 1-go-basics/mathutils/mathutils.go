@@ -1,58 +1,183 @@
 // Package mathutils provides basic mathematical operations.
+//
+// The functions are generic over every built-in integer and float type,
+// and types defined on them, so callers using int64, uint32 or float32
+// need no conversions. Plain arithmetic follows Go's own rules and wraps
+// around on integer overflow; the Checked and Saturating variants and the
+// math/big helpers are there for when that matters.
 package mathutils
 
 import (
+	"errors"
 	"fmt"
 	"math"
 )
 
-// Add returns the sum of two numbers.
-func Add(a, b int) int {
+// Signed is the set of signed integer types.
+type Signed interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+// Unsigned is the set of unsigned integer types.
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Integer is the set of integer types.
+type Integer interface {
+	Signed | Unsigned
+}
+
+// Float is the set of floating-point types.
+type Float interface {
+	~float32 | ~float64
+}
+
+// Number is the set of integer and floating-point types.
+type Number interface {
+	Integer | Float
+}
+
+// ErrDivisionByZero is returned when dividing by zero.
+var ErrDivisionByZero = errors.New("mathutils: division by zero")
+
+// Add returns the sum of two numbers. Integers wrap around on overflow;
+// use CheckedAdd or SaturatingAdd when that matters.
+func Add[T Number](a, b T) T {
 	return a + b
 }
 
-// Subtract returns the difference between two numbers.
-func Subtract(a, b int) int {
+// Subtract returns the difference between two numbers. Integers wrap
+// around on overflow; use CheckedSubtract or SaturatingSubtract when that
+// matters.
+func Subtract[T Number](a, b T) T {
 	return a - b
 }
 
-// Multiply returns the product of two numbers.
-func Multiply(a, b int) int {
+// Multiply returns the product of two numbers. Integers wrap around on
+// overflow; use CheckedMultiply or SaturatingMultiply when that matters.
+func Multiply[T Number](a, b T) T {
 	return a * b
 }
 
-// Divide returns the quotient of two numbers.
-// Returns an error if division by zero is attempted.
-func Divide(a, b int) (float64, error) {
+// Divide returns the quotient of two numbers. Integer division truncates
+// toward zero like Go's / operator; use DivMod for floored or Euclidean
+// division. Returns ErrDivisionByZero if b is zero, for floats as well,
+// and an *OverflowError for the most negative signed integer divided by -1.
+func Divide[T Number](a, b T) (T, error) {
 	if b == 0 {
-		return 0, fmt.Errorf("error: division by zero is not allowed")
+		return 0, ErrDivisionByZero
+	}
+	q := a / b
+	// Only MinInt / -1 yields a negative quotient from two negative integers.
+	if a < 0 && b < 0 && q < 0 {
+		return 0, &OverflowError{Op: "Divide", Operands: []any{a, b}}
+	}
+	return q, nil
+}
+
+// Abs returns the absolute value of x. The most negative signed integer
+// has no positive counterpart and is returned unchanged.
+func Abs[T Number](x T) T {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Min returns the smaller of a and b. If either is a float NaN, Min
+// returns NaN.
+func Min[T Number](a, b T) T {
+	return min(a, b)
+}
+
+// Max returns the larger of a and b. If either is a float NaN, Max
+// returns NaN.
+func Max[T Number](a, b T) T {
+	return max(a, b)
+}
+
+// Clamp limits x to the range [lo, hi]. It panics if lo > hi.
+func Clamp[T Number](x, lo, hi T) T {
+	if lo > hi {
+		panic(fmt.Sprintf("mathutils: Clamp range [%v, %v] is empty", lo, hi))
+	}
+	return min(max(x, lo), hi)
+}
+
+// Power raises base to the power of exp by repeated squaring, so integer
+// results are exact as long as they fit in T; they wrap around otherwise,
+// see CheckedPower. A negative exp gives 1 / base^-exp, which for integers
+// truncates toward zero like Divide and panics if base is zero; for floats
+// it is computed with math.Pow, so results near the subnormal range do not
+// overflow on the way. The exponent is a whole number; use math.Pow for a
+// fractional one.
+func Power[T Number](base T, exp int) T {
+	if exp < 0 && isFloat[T]() {
+		// Pow takes the exponent as a float64, which may round an odd exp
+		// to an even one; apply the sign separately.
+		b := float64(base)
+		result := math.Pow(math.Abs(b), float64(exp))
+		if math.Signbit(b) && exp&1 == 1 {
+			result = -result
+		}
+		return T(result)
+	}
+	if exp < 0 {
+		// 1 / base^-exp truncates to 0 unless |base| is 1; computing
+		// base^-exp first could wrap to 0 and divide by zero.
+		switch {
+		case base == 0:
+			panic(fmt.Sprintf("mathutils: Power of zero to negative exponent %d", exp))
+		case base == 1:
+			return 1
+		case base < 0 && base+1 == 0: // -1, written so unsigned T compiles
+			if exp&1 == 1 {
+				return base
+			}
+			return 1
+		}
+		return 0
+	}
+	result := T(1)
+	for exp > 0 {
+		if exp&1 == 1 {
+			result *= base
+		}
+		base *= base
+		exp >>= 1
 	}
-	return float64(a) / float64(b), nil
+	return result
 }
 
-// Power raises a number `base` to the power of `exp`.
-func Power(base, exp float64) float64 {
-	return math.Pow(base, exp)
+// isFloat reports whether T is a floating-point type.
+func isFloat[T Number]() bool {
+	return T(1)/2 != 0
 }
 
 // SquareRoot returns the square root of a number.
 // Returns an error if input is negative.
-func SquareRoot(num float64) (float64, error) {
+func SquareRoot[T Number](num T) (float64, error) {
 	if num < 0 {
 		return 0, fmt.Errorf("error: cannot compute square root of a negative number")
 	}
-	return math.Sqrt(num), nil
+	return math.Sqrt(float64(num)), nil
 }
 
 // Factorial calculates the factorial of a given number (n!).
-// Returns 1 if `n` is 0, and an error if `n` is negative.
-func Factorial(n int) (int, error) {
+// Returns 1 if `n` is 0, and an error if `n` is negative. Returns an
+// *OverflowError if n! does not fit in T, which for int64 is from 21 on;
+// use BigFactorial for exact large results.
+func Factorial[T Integer](n T) (T, error) {
 	if n < 0 {
 		return 0, fmt.Errorf("error: factorial is not defined for negative numbers")
 	}
-	result := 1
-	for i := 2; i <= n; i++ {
-		result *= i
+	result := T(1)
+	for i := T(2); i <= n; i++ {
+		var err error
+		if result, err = CheckedMultiply(result, i); err != nil {
+			return 0, &OverflowError{Op: "Factorial", Operands: []any{n}}
+		}
 	}
 	return result, nil
 }
@@ -0,0 +1,90 @@
+package mathutils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPowerFloat(t *testing.T) {
+	tests := []struct {
+		base float64
+		exp  int
+		want float64
+	}{
+		{2, 10, 1024},
+		{2, -3, 0.125},
+		{2, -1074, 5e-324}, // Smallest subnormal; 2^1074 alone overflows
+		{2, -1075, 0},
+		{0.5, 1074, 5e-324},
+		{-2, -3, -0.125},
+		{-2, -4, 0.0625},
+		{4, -2, 0.0625},
+		{0, -1, math.Inf(1)},
+		{math.Copysign(0, -1), -1, math.Inf(-1)},
+		{-1, math.MinInt, 1},
+		{-1, math.MinInt + 1, -1},
+		{3, 0, 1},
+	}
+	for _, tt := range tests {
+		if got := Power(tt.base, tt.exp); got != tt.want {
+			t.Errorf("Power(%v, %d) = %v, want %v", tt.base, tt.exp, got, tt.want)
+		}
+	}
+	if got := Power(float32(2), -149); got != math.SmallestNonzeroFloat32 {
+		t.Errorf("Power(float32(2), -149) = %v, want %v", got, float32(math.SmallestNonzeroFloat32))
+	}
+}
+
+func TestPowerInteger(t *testing.T) {
+	tests := []struct {
+		base int64
+		exp  int
+		want int64
+	}{
+		{3, 39, 4052555153018976267},
+		{-2, 63, math.MinInt64},
+		{2, 64, 0}, // Wraps around
+		{2, -1, 0}, // Truncates toward zero
+		{1, -5, 1},
+		{-1, -5, -1},
+		{7, 0, 1},
+		{-1, -4, 1},
+		{-1, math.MinInt, 1},
+		{-2, -1, 0},
+		{2, -64, 0}, // 2^64 wraps to 0 in int64
+		{math.MinInt64, -1, 0},
+	}
+	for _, tt := range tests {
+		if got := Power(tt.base, tt.exp); got != tt.want {
+			t.Errorf("Power(%d, %d) = %d, want %d", tt.base, tt.exp, got, tt.want)
+		}
+	}
+	if got := Power(uint8(3), 5); got != 243 {
+		t.Errorf("Power(uint8(3), 5) = %d, want 243", got)
+	}
+	// Negative exponents whose positive power wraps to 0 in T.
+	if got := Power[int](2, -64); got != 0 {
+		t.Errorf("Power[int](2, -64) = %d, want 0", got)
+	}
+	if got := Power[int8](2, -8); got != 0 {
+		t.Errorf("Power[int8](2, -8) = %d, want 0", got)
+	}
+	if got := Power[uint8](16, -2); got != 0 {
+		t.Errorf("Power[uint8](16, -2) = %d, want 0", got)
+	}
+	if got := Power[uint8](255, -1); got != 0 {
+		t.Errorf("Power[uint8](255, -1) = %d, want 0", got)
+	}
+	if got := Power[int8](-1, -7); got != -1 {
+		t.Errorf("Power[int8](-1, -7) = %d, want -1", got)
+	}
+}
+
+func TestPowerZeroNegativePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Power(0, -1) did not panic")
+		}
+	}()
+	Power(0, -1)
+}
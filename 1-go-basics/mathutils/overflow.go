@@ -0,0 +1,146 @@
+package mathutils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrOverflow is wrapped by every *OverflowError, so callers can test
+// for overflow with errors.Is.
+var ErrOverflow = errors.New("mathutils: integer overflow")
+
+// OverflowError reports an operation whose exact result does not fit in
+// its integer type.
+type OverflowError struct {
+	Op       string // Name of the operation, e.g. "Multiply"
+	Operands []any
+}
+
+func (e *OverflowError) Error() string {
+	operands := make([]string, len(e.Operands))
+	for i, x := range e.Operands {
+		operands[i] = fmt.Sprint(x)
+	}
+	typeName := "integer"
+	if len(e.Operands) > 0 {
+		typeName = fmt.Sprintf("%T", e.Operands[0])
+	}
+	return fmt.Sprintf("mathutils: %s(%s) overflows %s", e.Op, strings.Join(operands, ", "), typeName)
+}
+
+// Unwrap returns ErrOverflow.
+func (e *OverflowError) Unwrap() error {
+	return ErrOverflow
+}
+
+// CheckedAdd returns a + b, or an *OverflowError if the sum does not fit
+// in T.
+func CheckedAdd[T Integer](a, b T) (T, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, &OverflowError{Op: "Add", Operands: []any{a, b}}
+	}
+	return sum, nil
+}
+
+// CheckedSubtract returns a - b, or an *OverflowError if the difference
+// does not fit in T.
+func CheckedSubtract[T Integer](a, b T) (T, error) {
+	diff := a - b
+	if (b > 0 && diff > a) || (b < 0 && diff < a) {
+		return 0, &OverflowError{Op: "Subtract", Operands: []any{a, b}}
+	}
+	return diff, nil
+}
+
+// CheckedMultiply returns a * b, or an *OverflowError if the product does
+// not fit in T.
+func CheckedMultiply[T Integer](a, b T) (T, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	product := a * b
+	// MinInt * -1 wraps back to MinInt, and MinInt / -1 does too, so the
+	// division check alone would miss it; its sign gives it away.
+	if product/b != a || ((a < 0) == (b < 0) && product < 0) {
+		return 0, &OverflowError{Op: "Multiply", Operands: []any{a, b}}
+	}
+	return product, nil
+}
+
+// CheckedPower returns base raised to exp, or an *OverflowError if the
+// result does not fit in T. It returns an error if exp is negative,
+// since the result would not be an integer.
+func CheckedPower[T Integer](base T, exp int) (T, error) {
+	if exp < 0 {
+		return 0, fmt.Errorf("mathutils: negative exponent %d", exp)
+	}
+	switch {
+	case exp == 0 || base == 1:
+		return 1, nil
+	case base == 0:
+		return 0, nil
+	case base < 0 && base+1 == 0: // -1, written so unsigned T compiles
+		if exp%2 == 0 {
+			return 1, nil
+		}
+		return base, nil
+	}
+	// |base| >= 2 overflows within 64 steps, so the loop is short.
+	result := T(1)
+	for i := 0; i < exp; i++ {
+		var err error
+		if result, err = CheckedMultiply(result, base); err != nil {
+			return 0, &OverflowError{Op: "Power", Operands: []any{base, exp}}
+		}
+	}
+	return result, nil
+}
+
+// SaturatingAdd returns a + b, clamped to the range of T.
+func SaturatingAdd[T Integer](a, b T) T {
+	sum, err := CheckedAdd(a, b)
+	if err != nil {
+		return saturate[T](b > 0)
+	}
+	return sum
+}
+
+// SaturatingSubtract returns a - b, clamped to the range of T.
+func SaturatingSubtract[T Integer](a, b T) T {
+	diff, err := CheckedSubtract(a, b)
+	if err != nil {
+		return saturate[T](b < 0)
+	}
+	return diff
+}
+
+// SaturatingMultiply returns a * b, clamped to the range of T.
+func SaturatingMultiply[T Integer](a, b T) T {
+	product, err := CheckedMultiply(a, b)
+	if err != nil {
+		return saturate[T]((a < 0) == (b < 0))
+	}
+	return product
+}
+
+// saturate returns the bound of T an overflowing result was heading for.
+func saturate[T Integer](positive bool) T {
+	var zero T
+	if ^zero > zero { // Unsigned
+		if positive {
+			return ^zero
+		}
+		return zero
+	}
+	// Build the largest signed value bit by bit, since T's size is unknown.
+	largest := T(1)
+	for largest<<1 > 0 {
+		largest = largest<<1 | 1
+	}
+	if positive {
+		return largest
+	}
+	return -largest - 1
+}
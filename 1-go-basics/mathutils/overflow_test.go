@@ -0,0 +1,200 @@
+package mathutils
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+// opCase is one row of a Checked* table. overflow means the operation
+// fails; want is then the Saturating* result, if the operation has one.
+type opCase[T Integer] struct {
+	a, b     T
+	want     T
+	overflow bool
+}
+
+// testOp runs cases through a checked operation and, if saturating is
+// not nil, through its saturating counterpart.
+func testOp[T Integer](t *testing.T, name string, checked func(a, b T) (T, error), saturating func(a, b T) T, cases []opCase[T]) {
+	t.Helper()
+	for _, tt := range cases {
+		got, err := checked(tt.a, tt.b)
+		switch {
+		case tt.overflow && !errors.Is(err, ErrOverflow):
+			t.Errorf("Checked%s(%v, %v) = %v, %v, want ErrOverflow", name, tt.a, tt.b, got, err)
+		case !tt.overflow && (err != nil || got != tt.want):
+			t.Errorf("Checked%s(%v, %v) = %v, %v, want %v", name, tt.a, tt.b, got, err, tt.want)
+		}
+		if saturating != nil {
+			if got := saturating(tt.a, tt.b); got != tt.want {
+				t.Errorf("Saturating%s(%v, %v) = %v, want %v", name, tt.a, tt.b, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	testOp(t, "Add", CheckedAdd[int8], SaturatingAdd[int8], []opCase[int8]{
+		{100, 27, 127, false},
+		{100, 28, 127, true},
+		{-100, -28, -128, false},
+		{-100, -29, -128, true},
+		{127, -128, -1, false},
+		{-128, 0, -128, false},
+	})
+	testOp(t, "Add", CheckedAdd[uint8], SaturatingAdd[uint8], []opCase[uint8]{
+		{200, 55, 255, false},
+		{200, 56, 255, true},
+		{255, 255, 255, true},
+		{0, 0, 0, false},
+	})
+	testOp(t, "Add", CheckedAdd[int64], SaturatingAdd[int64], []opCase[int64]{
+		{math.MaxInt64, 0, math.MaxInt64, false},
+		{math.MaxInt64, 1, math.MaxInt64, true},
+		{math.MinInt64, -1, math.MinInt64, true},
+		{math.MinInt64, math.MaxInt64, -1, false},
+	})
+}
+
+func TestSubtract(t *testing.T) {
+	testOp(t, "Subtract", CheckedSubtract[int8], SaturatingSubtract[int8], []opCase[int8]{
+		{-128, 1, -128, true},
+		{-127, 1, -128, false},
+		{0, -128, 127, true},
+		{-1, -128, 127, false},
+		{127, -1, 127, true},
+	})
+	testOp(t, "Subtract", CheckedSubtract[uint8], SaturatingSubtract[uint8], []opCase[uint8]{
+		{5, 5, 0, false},
+		{5, 6, 0, true},
+		{0, 255, 0, true},
+		{255, 0, 255, false},
+	})
+	testOp(t, "Subtract", CheckedSubtract[int64], SaturatingSubtract[int64], []opCase[int64]{
+		{math.MinInt64, 1, math.MinInt64, true},
+		{math.MaxInt64, -1, math.MaxInt64, true},
+		{-1, math.MaxInt64, math.MinInt64, false},
+		{0, math.MinInt64, math.MaxInt64, true},
+	})
+}
+
+func TestMultiply(t *testing.T) {
+	testOp(t, "Multiply", CheckedMultiply[int8], SaturatingMultiply[int8], []opCase[int8]{
+		{-128, -1, 127, true},
+		{-1, -128, 127, true},
+		{-128, 1, -128, false},
+		{-64, 2, -128, false},
+		{64, 2, 127, true},
+		{-64, -2, 127, true},
+		{16, -9, -128, true},
+		{0, -128, 0, false},
+		{11, 11, 121, false},
+	})
+	testOp(t, "Multiply", CheckedMultiply[uint8], SaturatingMultiply[uint8], []opCase[uint8]{
+		{15, 17, 255, false},
+		{16, 16, 255, true},
+		{128, 2, 255, true},
+		{255, 0, 0, false},
+	})
+	testOp(t, "Multiply", CheckedMultiply[int64], SaturatingMultiply[int64], []opCase[int64]{
+		{math.MinInt64, -1, math.MaxInt64, true},
+		{-1, math.MinInt64, math.MaxInt64, true},
+		{1 << 62, 2, math.MaxInt64, true},
+		{-1 << 62, 2, math.MinInt64, false},
+		{1 << 32, 1 << 31, math.MaxInt64, true},
+		{3037000499, 3037000499, 9223372030926249001, false},
+	})
+}
+
+func TestCheckedPower(t *testing.T) {
+	testOp(t, "Power", func(a, b int8) (int8, error) { return CheckedPower(a, int(b)) }, nil, []opCase[int8]{
+		{-2, 7, -128, false},
+		{-2, 8, 0, true},
+		{2, 6, 64, false},
+		{2, 7, 0, true},
+		{-1, 127, -1, false},
+		{-1, 126, 1, false},
+		{0, 0, 1, false},
+		{0, 5, 0, false},
+		{1, 127, 1, false},
+		{-128, 1, -128, false},
+		{-128, 2, 0, true},
+	})
+	testOp(t, "Power", func(a, b uint8) (uint8, error) { return CheckedPower(a, int(b)) }, nil, []opCase[uint8]{
+		{2, 7, 128, false},
+		{2, 8, 0, true},
+		{255, 1, 255, false},
+		{255, 2, 0, true},
+		{15, 2, 225, false},
+	})
+	testOp(t, "Power", func(a, b int64) (int64, error) { return CheckedPower(a, int(b)) }, nil, []opCase[int64]{
+		{-2, 63, math.MinInt64, false},
+		{2, 63, 0, true},
+		{-1, math.MaxInt32, -1, false},
+		{10, 18, 1e18, false},
+		{10, 19, 0, true},
+	})
+
+	if _, err := CheckedPower(2, -1); err == nil || errors.Is(err, ErrOverflow) {
+		t.Errorf("CheckedPower(2, -1) = %v, want a negative exponent error", err)
+	}
+	_, err := CheckedPower(int8(-2), 8)
+	var oe *OverflowError
+	if !errors.As(err, &oe) || oe.Op != "Power" || err.Error() != "mathutils: Power(-2, 8) overflows int8" {
+		t.Errorf("CheckedPower(int8(-2), 8) error = %v", err)
+	}
+}
+
+func TestBigFactorial(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "1"},
+		{1, "1"},
+		{20, "2432902008176640000"},
+		{25, "15511210043330985984000000"},
+	}
+	for _, tt := range tests {
+		got, err := BigFactorial(tt.n)
+		if err != nil || got.String() != tt.want {
+			t.Errorf("BigFactorial(%d) = %v, %v, want %s", tt.n, got, err, tt.want)
+		}
+	}
+	if got, err := BigFactorial(-1); err == nil {
+		t.Errorf("BigFactorial(-1) = %v, want an error", got)
+	}
+}
+
+func TestBigPower(t *testing.T) {
+	tests := []struct {
+		base int64
+		exp  int
+		want string
+	}{
+		{2, 0, "1"},
+		{0, 0, "1"},
+		{2, 64, "18446744073709551616"},
+		{-2, 63, "-9223372036854775808"},
+		{-3, 41, "-36472996377170786403"},
+		{-1, 1001, "-1"},
+	}
+	for _, tt := range tests {
+		base := big.NewInt(tt.base)
+		got, err := BigPower(base, tt.exp)
+		if err != nil || got.String() != tt.want {
+			t.Errorf("BigPower(%d, %d) = %v, %v, want %s", tt.base, tt.exp, got, err, tt.want)
+		}
+		if base.Int64() != tt.base {
+			t.Errorf("BigPower(%d, %d) changed its base to %v", tt.base, tt.exp, base)
+		}
+	}
+	if got, err := BigPower(big.NewInt(2), -1); err == nil {
+		t.Errorf("BigPower(2, -1) = %v, want an error", got)
+	}
+	if got, err := BigPower(nil, 3); err == nil {
+		t.Errorf("BigPower(nil, 3) = %v, want an error", got)
+	}
+}
@@ -0,0 +1,168 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// BloomFilter answers "have I seen this item?" with no false negatives
+// and a tunable rate of false positives.
+type BloomFilter struct {
+	words []uint64
+	m     uint64 // number of bits
+	k     uint32 // number of hash functions
+	added uint64 // items added, including duplicates
+}
+
+// NewBloomFilter returns a filter sized so that after expectedItems
+// distinct items have been added, Contains reports a false positive with
+// probability about falsePositiveRate. It panics if expectedItems is not
+// positive or falsePositiveRate is not in (0, 1).
+func NewBloomFilter(expectedItems int, falsePositiveRate float64) *BloomFilter {
+	if expectedItems <= 0 {
+		panic(fmt.Sprintf("sketch: expected items %d must be positive", expectedItems))
+	}
+	if !(falsePositiveRate > 0 && falsePositiveRate < 1) {
+		panic(fmt.Sprintf("sketch: false positive rate %v must be in (0, 1)", falsePositiveRate))
+	}
+	n := float64(expectedItems)
+	m := math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := max(1, math.Round(m/n*math.Ln2))
+	return newBloomFilter(uint64(m), uint32(k))
+}
+
+func newBloomFilter(m uint64, k uint32) *BloomFilter {
+	return &BloomFilter{words: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+// Bits returns the size of the filter in bits.
+func (b *BloomFilter) Bits() uint64 {
+	return b.m
+}
+
+// HashFunctions returns the number of hash functions used per item.
+func (b *BloomFilter) HashFunctions() int {
+	return int(b.k)
+}
+
+// Added returns how many items have been added, counting duplicates.
+func (b *BloomFilter) Added() uint64 {
+	return b.added
+}
+
+func (b *BloomFilter) add(h uint64) {
+	h1, h2 := hashPair(h)
+	for i := uint64(0); i < uint64(b.k); i++ {
+		bit := (h1 + i*h2) % b.m
+		b.words[bit/64] |= 1 << (bit % 64)
+	}
+	b.added++
+}
+
+func (b *BloomFilter) contains(h uint64) bool {
+	h1, h2 := hashPair(h)
+	for i := uint64(0); i < uint64(b.k); i++ {
+		bit := (h1 + i*h2) % b.m
+		if b.words[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Add records item.
+func (b *BloomFilter) Add(item []byte) { b.add(hash(item)) }
+
+// AddString records item.
+func (b *BloomFilter) AddString(item string) { b.add(hash(item)) }
+
+// Contains reports whether item may have been added. A false result is
+// always correct; a true result is wrong with the filter's false
+// positive probability.
+func (b *BloomFilter) Contains(item []byte) bool { return b.contains(hash(item)) }
+
+// ContainsString is Contains for a string item.
+func (b *BloomFilter) ContainsString(item string) bool { return b.contains(hash(item)) }
+
+// FalsePositiveRate estimates the current false positive probability
+// from the fraction of bits set.
+func (b *BloomFilter) FalsePositiveRate() float64 {
+	set := 0
+	for _, w := range b.words {
+		set += bits.OnesCount64(w)
+	}
+	return math.Pow(float64(set)/float64(b.m), float64(b.k))
+}
+
+// Merge adds every item of other to b. Both filters must have been
+// created with the same size and number of hash functions.
+func (b *BloomFilter) Merge(other *BloomFilter) error {
+	if b.m != other.m || b.k != other.k {
+		return fmt.Errorf("%w: bloom filter %d bits/%d hashes vs %d bits/%d hashes",
+			ErrIncompatible, b.m, b.k, other.m, other.k)
+	}
+	for i, w := range other.words {
+		b.words[i] |= w
+	}
+	b.added += other.added
+	return nil
+}
+
+// Bloom filter encoding: header | m (8) | k (4) | added (8) | words.
+const bloomHeaderSize = 2 + 8 + 4 + 8
+
+// Limits UnmarshalBinary enforces on untrusted input. NewBloomFilter never
+// picks more than about 1075 hashes, as that already gives the smallest
+// false positive rate a float64 can express; the word limit keeps the
+// byte length within an int.
+const (
+	maxBloomHashes = 2048
+	maxBloomWords  = math.MaxInt / 8
+)
+
+// MarshalBinary encodes the filter.
+func (b *BloomFilter) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, bloomHeaderSize+8*len(b.words))
+	rest := header(buf, tagBloom)
+	binary.LittleEndian.PutUint64(rest, b.m)
+	binary.LittleEndian.PutUint32(rest[8:], b.k)
+	binary.LittleEndian.PutUint64(rest[12:], b.added)
+	putUint64s(rest[20:], b.words)
+	return buf, nil
+}
+
+// UnmarshalBinary replaces the filter with one encoded by MarshalBinary.
+func (b *BloomFilter) UnmarshalBinary(data []byte) error {
+	rest, err := checkHeader(data, tagBloom, "bloom filter")
+	if err != nil {
+		return err
+	}
+	if len(rest) < bloomHeaderSize-2 {
+		return fmt.Errorf("%w: bloom filter header truncated", ErrInvalidData)
+	}
+	m := binary.LittleEndian.Uint64(rest)
+	k := binary.LittleEndian.Uint32(rest[8:])
+	if m == 0 || k == 0 {
+		return fmt.Errorf("%w: bloom filter has %d bits and %d hashes", ErrInvalidData, m, k)
+	}
+	if k > maxBloomHashes {
+		return fmt.Errorf("%w: bloom filter has %d hashes, above the maximum %d", ErrInvalidData, k, maxBloomHashes)
+	}
+	// Written without (m+63)/64 or 8*words, both of which can wrap.
+	words := m/64 + min(m%64, 1)
+	if words > maxBloomWords {
+		return fmt.Errorf("%w: bloom filter of %d bits is too large", ErrInvalidData, m)
+	}
+	payload := len(rest) - 20
+	if payload%8 != 0 || uint64(payload/8) != words {
+		return fmt.Errorf("%w: bloom filter of %d bits needs %d words of bits, got %d bytes",
+			ErrInvalidData, m, words, payload)
+	}
+	nb := newBloomFilter(m, k)
+	nb.added = binary.LittleEndian.Uint64(rest[12:])
+	readUint64s(rest[20:], nb.words)
+	*b = *nb
+	return nil
+}
@@ -0,0 +1,134 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// CountMinSketch estimates how often each item occurs in a stream. An
+// estimate is never below the true count and, with probability 1-delta,
+// exceeds it by at most epsilon times the total of all counts.
+type CountMinSketch struct {
+	width    uint32
+	depth    uint32
+	counters []uint64 // depth rows of width counters
+	total    uint64
+}
+
+// NewCountMinSketch returns a sketch with error bound epsilon and failure
+// probability delta: width = ceil(e/epsilon), depth = ceil(ln(1/delta)).
+// It panics unless both are in (0, 1).
+func NewCountMinSketch(epsilon, delta float64) *CountMinSketch {
+	if !(epsilon > 0 && epsilon < 1) || !(delta > 0 && delta < 1) {
+		panic(fmt.Sprintf("sketch: epsilon %v and delta %v must be in (0, 1)", epsilon, delta))
+	}
+	width := math.Ceil(math.E / epsilon)
+	depth := math.Ceil(math.Log(1 / delta))
+	return NewCountMinSketchSize(int(width), int(depth))
+}
+
+// NewCountMinSketchSize returns a sketch with the given number of
+// counters per row and rows. It panics if either is not positive.
+func NewCountMinSketchSize(width, depth int) *CountMinSketch {
+	if width <= 0 || depth <= 0 || width > math.MaxUint32 || depth > math.MaxUint32 {
+		panic(fmt.Sprintf("sketch: count-min size %dx%d out of range", width, depth))
+	}
+	return &CountMinSketch{width: uint32(width), depth: uint32(depth), counters: make([]uint64, width*depth)}
+}
+
+// Width returns the number of counters per row.
+func (c *CountMinSketch) Width() int { return int(c.width) }
+
+// Depth returns the number of rows.
+func (c *CountMinSketch) Depth() int { return int(c.depth) }
+
+// Total returns the sum of all counts added.
+func (c *CountMinSketch) Total() uint64 { return c.total }
+
+func (c *CountMinSketch) add(h, count uint64) {
+	h1, h2 := hashPair(h)
+	for row := uint64(0); row < uint64(c.depth); row++ {
+		col := (h1 + row*h2) % uint64(c.width)
+		c.counters[row*uint64(c.width)+col] += count
+	}
+	c.total += count
+}
+
+func (c *CountMinSketch) estimate(h uint64) uint64 {
+	h1, h2 := hashPair(h)
+	est := uint64(math.MaxUint64)
+	for row := uint64(0); row < uint64(c.depth); row++ {
+		col := (h1 + row*h2) % uint64(c.width)
+		est = min(est, c.counters[row*uint64(c.width)+col])
+	}
+	return est
+}
+
+// Add adds count occurrences of item.
+func (c *CountMinSketch) Add(item []byte, count uint64) { c.add(hash(item), count) }
+
+// AddString adds count occurrences of item.
+func (c *CountMinSketch) AddString(item string, count uint64) { c.add(hash(item), count) }
+
+// Estimate returns the estimated number of occurrences of item.
+func (c *CountMinSketch) Estimate(item []byte) uint64 { return c.estimate(hash(item)) }
+
+// EstimateString is Estimate for a string item.
+func (c *CountMinSketch) EstimateString(item string) uint64 { return c.estimate(hash(item)) }
+
+// Merge adds the counts of other to c. Both sketches must have the same
+// width and depth.
+func (c *CountMinSketch) Merge(other *CountMinSketch) error {
+	if c.width != other.width || c.depth != other.depth {
+		return fmt.Errorf("%w: count-min %dx%d vs %dx%d", ErrIncompatible, c.width, c.depth, other.width, other.depth)
+	}
+	for i, v := range other.counters {
+		c.counters[i] += v
+	}
+	c.total += other.total
+	return nil
+}
+
+// Count-Min encoding: header | width (4) | depth (4) | total (8) | counters.
+const countMinHeaderSize = 2 + 4 + 4 + 8
+
+// MarshalBinary encodes the sketch.
+func (c *CountMinSketch) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, countMinHeaderSize+8*len(c.counters))
+	rest := header(buf, tagCountMin)
+	binary.LittleEndian.PutUint32(rest, c.width)
+	binary.LittleEndian.PutUint32(rest[4:], c.depth)
+	binary.LittleEndian.PutUint64(rest[8:], c.total)
+	putUint64s(rest[16:], c.counters)
+	return buf, nil
+}
+
+// UnmarshalBinary replaces the sketch with one encoded by MarshalBinary.
+func (c *CountMinSketch) UnmarshalBinary(data []byte) error {
+	rest, err := checkHeader(data, tagCountMin, "count-min sketch")
+	if err != nil {
+		return err
+	}
+	if len(rest) < countMinHeaderSize-2 {
+		return fmt.Errorf("%w: count-min header truncated", ErrInvalidData)
+	}
+	width := binary.LittleEndian.Uint32(rest)
+	depth := binary.LittleEndian.Uint32(rest[4:])
+	if width == 0 || depth == 0 {
+		return fmt.Errorf("%w: count-min size %dx%d", ErrInvalidData, width, depth)
+	}
+	// width*depth fits in a uint64, but 8 times it may not, so compare
+	// counter counts rather than byte counts.
+	n := uint64(width) * uint64(depth)
+	payload := len(rest) - 16
+	if n > math.MaxInt/8 || payload%8 != 0 || uint64(payload/8) != n {
+		return fmt.Errorf("%w: count-min %dx%d needs %d counters, got %d bytes",
+			ErrInvalidData, width, depth, n, payload)
+	}
+	nc := &CountMinSketch{width: width, depth: depth, total: binary.LittleEndian.Uint64(rest[8:])}
+	nc.counters = make([]uint64, n)
+	readUint64s(rest[16:], nc.counters)
+	*c = *nc
+	return nil
+}
@@ -0,0 +1,128 @@
+package sketch
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// HyperLogLog estimates the number of distinct items in a stream using
+// 2^precision one-byte registers. The standard error is about
+// 1.04/sqrt(2^precision), so precision 14 (16 KiB) gives roughly 0.8%.
+type HyperLogLog struct {
+	p         uint8
+	registers []uint8
+}
+
+// Precision limits for NewHyperLogLog.
+const (
+	MinPrecision = 4
+	MaxPrecision = 18
+)
+
+// NewHyperLogLog returns an empty HyperLogLog. It panics unless
+// MinPrecision <= precision <= MaxPrecision.
+func NewHyperLogLog(precision int) *HyperLogLog {
+	if precision < MinPrecision || precision > MaxPrecision {
+		panic(fmt.Sprintf("sketch: precision %d out of range [%d, %d]", precision, MinPrecision, MaxPrecision))
+	}
+	return &HyperLogLog{p: uint8(precision), registers: make([]uint8, 1<<precision)}
+}
+
+// Precision returns the precision the HyperLogLog was created with.
+func (h *HyperLogLog) Precision() int { return int(h.p) }
+
+// add uses the top p bits of the hash to pick a register and stores the
+// position of the first 1 bit among the rest, if it is the highest seen.
+func (h *HyperLogLog) add(x uint64) {
+	idx := x >> (64 - h.p)
+	rank := uint8(bits.LeadingZeros64(x<<h.p|1<<(h.p-1)) + 1)
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// Add records item.
+func (h *HyperLogLog) Add(item []byte) { h.add(hash(item)) }
+
+// AddString records item.
+func (h *HyperLogLog) AddString(item string) { h.add(hash(item)) }
+
+// Count returns the estimated number of distinct items added.
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(len(h.registers))
+	sum, zeros := 0.0, 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	var alpha float64
+	switch len(h.registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+	estimate := alpha * m * m / sum
+	// With few items many registers are still empty and linear counting
+	// is more accurate. A 64-bit hash needs no large-range correction.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// Merge adds every item of other to h, so Count estimates the size of the
+// union. Both must have the same precision.
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	if h.p != other.p {
+		return fmt.Errorf("%w: hyperloglog precision %d vs %d", ErrIncompatible, h.p, other.p)
+	}
+	for i, r := range other.registers {
+		h.registers[i] = max(h.registers[i], r)
+	}
+	return nil
+}
+
+// HyperLogLog encoding: header | precision (1) | registers.
+
+// MarshalBinary encodes the HyperLogLog.
+func (h *HyperLogLog) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 3+len(h.registers))
+	rest := header(buf, tagHyperLogLog)
+	rest[0] = h.p
+	copy(rest[1:], h.registers)
+	return buf, nil
+}
+
+// UnmarshalBinary replaces h with a HyperLogLog encoded by MarshalBinary.
+func (h *HyperLogLog) UnmarshalBinary(data []byte) error {
+	rest, err := checkHeader(data, tagHyperLogLog, "hyperloglog")
+	if err != nil {
+		return err
+	}
+	if len(rest) < 1 {
+		return fmt.Errorf("%w: hyperloglog header truncated", ErrInvalidData)
+	}
+	p := int(rest[0])
+	if p < MinPrecision || p > MaxPrecision {
+		return fmt.Errorf("%w: hyperloglog precision %d out of range [%d, %d]", ErrInvalidData, p, MinPrecision, MaxPrecision)
+	}
+	if len(rest)-1 != 1<<p {
+		return fmt.Errorf("%w: hyperloglog of precision %d needs %d registers, got %d", ErrInvalidData, p, 1<<p, len(rest)-1)
+	}
+	maxRank := uint8(64 - p + 1)
+	for i, r := range rest[1:] {
+		if r > maxRank {
+			return fmt.Errorf("%w: hyperloglog register %d holds %d, above the maximum %d", ErrInvalidData, i, r, maxRank)
+		}
+	}
+	*h = HyperLogLog{p: uint8(p), registers: append([]uint8(nil), rest[1:]...)}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Package sketch provides probabilistic data structures that summarize
+// streams too large to hold in memory: a Bloom filter for membership, a
+// Count-Min sketch for frequencies and HyperLogLog for distinct counts.
+//
+// Each structure uses a fixed amount of memory chosen up front, can be
+// merged with another one built with the same parameters, and implements
+// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler so it can be
+// stored or sent to another process. Hashing is deterministic, so sketches
+// built in different processes are compatible.
+package sketch
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Errors returned by Merge and UnmarshalBinary.
+var (
+	ErrIncompatible = errors.New("sketch: sketches have different parameters")
+	ErrInvalidData  = errors.New("sketch: invalid encoded data")
+)
+
+// Encoded sketches start with a type tag and a format version.
+const (
+	tagBloom       byte = 'B'
+	tagCountMin    byte = 'C'
+	tagHyperLogLog byte = 'H'
+	formatVersion  byte = 1
+)
+
+// hash returns a 64-bit hash of data: FNV-1a followed by the MurmurHash3
+// finalizer, which spreads FNV's weak low bits across the whole word.
+func hash[S ~string | ~[]byte](data S) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(data); i++ {
+		h ^= uint64(data[i])
+		h *= 1099511628211
+	}
+	return mix64(h)
+}
+
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// hashPair derives two hashes for double hashing: the i-th of k hash
+// functions is h1 + i*h2. h2 is odd so it never degenerates to a constant.
+func hashPair(h uint64) (uint64, uint64) {
+	return h, mix64(h^0x9e3779b97f4a7c15) | 1
+}
+
+// header writes the tag and version and returns the rest of buf.
+func header(buf []byte, tag byte) []byte {
+	buf[0], buf[1] = tag, formatVersion
+	return buf[2:]
+}
+
+// checkHeader validates the tag and version and returns the rest of data.
+func checkHeader(data []byte, tag byte, name string) ([]byte, error) {
+	if len(data) < 2 || data[0] != tag {
+		return nil, fmt.Errorf("%w: not a %s", ErrInvalidData, name)
+	}
+	if data[1] != formatVersion {
+		return nil, fmt.Errorf("%w: unsupported %s version %d", ErrInvalidData, name, data[1])
+	}
+	return data[2:], nil
+}
+
+func putUint64s(buf []byte, values []uint64) {
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(buf[8*i:], v)
+	}
+}
+
+func readUint64s(data []byte, values []uint64) {
+	for i := range values {
+		values[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+}
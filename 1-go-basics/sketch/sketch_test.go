@@ -0,0 +1,146 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestBloomFilterRoundTrip(t *testing.T) {
+	b := NewBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		b.AddString(fmt.Sprint("item-", i))
+	}
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got BloomFilter
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got.Bits() != b.Bits() || got.HashFunctions() != b.HashFunctions() || got.Added() != b.Added() {
+		t.Fatalf("decoded %d bits/%d hashes/%d added, want %d/%d/%d",
+			got.Bits(), got.HashFunctions(), got.Added(), b.Bits(), b.HashFunctions(), b.Added())
+	}
+	for i := 0; i < 1000; i++ {
+		if item := fmt.Sprint("item-", i); !got.ContainsString(item) {
+			t.Fatalf("decoded filter lost %q", item)
+		}
+	}
+}
+
+func TestCountMinSketchRoundTrip(t *testing.T) {
+	c := NewCountMinSketchSize(64, 4)
+	for i := 0; i < 100; i++ {
+		c.AddString(fmt.Sprint("item-", i%10), uint64(i))
+	}
+	data, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got CountMinSketch
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got.Width() != 64 || got.Depth() != 4 || got.Total() != c.Total() {
+		t.Fatalf("decoded %dx%d total %d, want 64x4 total %d", got.Width(), got.Depth(), got.Total(), c.Total())
+	}
+	for i := 0; i < 10; i++ {
+		item := fmt.Sprint("item-", i)
+		if got.EstimateString(item) != c.EstimateString(item) {
+			t.Fatalf("estimate of %q is %d, want %d", item, got.EstimateString(item), c.EstimateString(item))
+		}
+	}
+}
+
+func TestHyperLogLogRoundTrip(t *testing.T) {
+	h := NewHyperLogLog(10)
+	for i := 0; i < 5000; i++ {
+		h.AddString(fmt.Sprint("item-", i))
+	}
+	data, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got HyperLogLog
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got.Count() != h.Count() {
+		t.Fatalf("decoded count %d, want %d", got.Count(), h.Count())
+	}
+}
+
+// bloomData encodes a Bloom filter header followed by words of zero bits.
+func bloomData(m uint64, k uint32, words int) []byte {
+	buf := make([]byte, bloomHeaderSize+8*words)
+	rest := header(buf, tagBloom)
+	binary.LittleEndian.PutUint64(rest, m)
+	binary.LittleEndian.PutUint32(rest[8:], k)
+	return buf
+}
+
+// countMinData encodes a Count-Min header followed by n zero counters.
+func countMinData(width, depth uint32, n int) []byte {
+	buf := make([]byte, countMinHeaderSize+8*n)
+	rest := header(buf, tagCountMin)
+	binary.LittleEndian.PutUint32(rest, width)
+	binary.LittleEndian.PutUint32(rest[4:], depth)
+	return buf
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		dst  interface{ UnmarshalBinary([]byte) error }
+		data []byte
+	}{
+		{"bloom empty", &BloomFilter{}, nil},
+		{"bloom wrong tag", &BloomFilter{}, []byte{tagCountMin, formatVersion}},
+		{"bloom wrong version", &BloomFilter{}, []byte{tagBloom, formatVersion + 1}},
+		{"bloom truncated header", &BloomFilter{}, bloomData(64, 1, 0)[:10]},
+		{"bloom zero bits", &BloomFilter{}, bloomData(0, 1, 0)},
+		{"bloom zero hashes", &BloomFilter{}, bloomData(64, 0, 1)},
+		{"bloom too many hashes", &BloomFilter{}, bloomData(64, math.MaxUint32, 1)},
+		{"bloom missing words", &BloomFilter{}, bloomData(65, 1, 1)},
+		{"bloom extra words", &BloomFilter{}, bloomData(64, 1, 2)},
+		{"bloom partial word", &BloomFilter{}, bloomData(64, 1, 1)[:bloomHeaderSize+4]},
+		// (m+63)/64 wraps to 0 and used to pass the length check.
+		{"bloom max bits", &BloomFilter{}, bloomData(math.MaxUint64, 1, 0)},
+		{"bloom huge bits", &BloomFilter{}, bloomData(math.MaxUint64-63, 1, 0)},
+		{"count-min empty", &CountMinSketch{}, nil},
+		{"count-min truncated header", &CountMinSketch{}, countMinData(1, 1, 0)[:8]},
+		{"count-min zero width", &CountMinSketch{}, countMinData(0, 4, 0)},
+		{"count-min zero depth", &CountMinSketch{}, countMinData(4, 0, 0)},
+		{"count-min missing counters", &CountMinSketch{}, countMinData(4, 4, 15)},
+		{"count-min partial counter", &CountMinSketch{}, countMinData(1, 1, 1)[:countMinHeaderSize+4]},
+		// 8*width*depth wraps to 0 and used to panic in makeslice.
+		{"count-min 2^31 square", &CountMinSketch{}, countMinData(1<<31, 1<<31, 0)},
+		{"count-min max size", &CountMinSketch{}, countMinData(math.MaxUint32, math.MaxUint32, 0)},
+		{"hyperloglog low precision", &HyperLogLog{}, []byte{tagHyperLogLog, formatVersion, MinPrecision - 1}},
+		{"hyperloglog missing registers", &HyperLogLog{}, []byte{tagHyperLogLog, formatVersion, MinPrecision, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dst.UnmarshalBinary(tt.data)
+			if !errors.Is(err, ErrInvalidData) {
+				t.Fatalf("UnmarshalBinary = %v, want ErrInvalidData", err)
+			}
+		})
+	}
+}
+
+func TestMergeIncompatible(t *testing.T) {
+	if err := NewBloomFilter(100, 0.01).Merge(NewBloomFilter(100, 0.001)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("bloom Merge = %v, want ErrIncompatible", err)
+	}
+	if err := NewCountMinSketchSize(8, 2).Merge(NewCountMinSketchSize(8, 3)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("count-min Merge = %v, want ErrIncompatible", err)
+	}
+	if err := NewHyperLogLog(8).Merge(NewHyperLogLog(9)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("hyperloglog Merge = %v, want ErrIncompatible", err)
+	}
+}
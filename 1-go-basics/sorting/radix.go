@@ -0,0 +1,229 @@
+package sorting
+
+import "unsafe"
+
+// ======================================================
+// Radix Sorts
+// ======================================================
+
+// Integer is a constraint that permits any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// radixBuckets is the number of buckets per pass; keys are split into bytes.
+const radixBuckets = 256
+
+// sizeOf returns the size in bytes of a value of type T.
+func sizeOf[T any]() int {
+	var zero T
+	return int(unsafe.Sizeof(zero))
+}
+
+// radixKey maps v to an unsigned key with the same ordering by flipping
+// the sign bit of signed types. Keys use only the low bits of T's width.
+func radixKey[T Integer](v T) uint64 {
+	var zero T
+	bits := sizeOf[T]() * 8
+	k := uint64(v)
+	if bits < 64 {
+		k &= 1<<bits - 1 // Drop the sign extension of narrow signed types
+	}
+	if ^zero < 0 { // Signed type
+		k ^= 1 << (bits - 1)
+	}
+	return k
+}
+
+// RadixSortLSD sorts integers in ascending order, one byte at a time
+// from the least significant byte. It is stable, makes no comparisons
+// and runs in O(n * size of T). Passes where every key has the same byte
+// are skipped.
+func RadixSortLSD[T Integer](x []T) Stats {
+	var stats Stats
+	if len(x) < 2 {
+		return stats
+	}
+	stats.Allocations++
+	stats.AllocBytes += len(x) * sizeOf[T]()
+	src, dst := x, make([]T, len(x))
+	for shift := 0; shift < sizeOf[T]()*8; shift += 8 {
+		var count [radixBuckets + 1]int
+		for _, v := range src {
+			count[(radixKey(v)>>shift)&0xff+1]++
+		}
+		if count[(radixKey(src[0])>>shift)&0xff+1] == len(src) {
+			continue // Every key has the same byte here
+		}
+		for i := 1; i <= radixBuckets; i++ {
+			count[i] += count[i-1]
+		}
+		for _, v := range src {
+			b := (radixKey(v) >> shift) & 0xff
+			dst[count[b]] = v
+			count[b]++
+		}
+		stats.Writes += len(src)
+		src, dst = dst, src
+	}
+	if &src[0] != &x[0] {
+		copy(x, src)
+		stats.Writes += len(x)
+	}
+	return stats
+}
+
+// RadixSortMSD sorts integers in ascending order, one byte at a time
+// from the most significant byte, recursing into each bucket. Small
+// buckets fall back to insertion sort. It is stable.
+func RadixSortMSD[T Integer](x []T) Stats {
+	s := newSorter(func(a, b T) int {
+		switch ka, kb := radixKey(a), radixKey(b); {
+		case ka < kb:
+			return -1
+		case ka > kb:
+			return 1
+		}
+		return 0
+	})
+	if len(x) > 1 {
+		buf := s.scratch(len(x), sizeOf[T]())
+		msdInts(s, x, buf, sizeOf[T]()*8-8)
+	}
+	return s.stats
+}
+
+func msdInts[T Integer](s *sorter[T], x, buf []T, shift int) {
+	if len(x) <= insertionThreshold {
+		s.insertionSort(x)
+		return
+	}
+	var count [radixBuckets + 1]int
+	digit := func(v T) uint64 {
+		return (radixKey(v) >> shift) & 0xff
+	}
+	for _, v := range x {
+		count[digit(v)+1]++
+	}
+	for i := 1; i <= radixBuckets; i++ {
+		count[i] += count[i-1]
+	}
+	start := count
+	for _, v := range x {
+		d := digit(v)
+		buf[count[d]] = v
+		count[d]++
+	}
+	copy(x, buf[:len(x)])
+	s.stats.Writes += 2 * len(x)
+	if shift == 0 {
+		return
+	}
+	for b := 0; b < radixBuckets; b++ {
+		if lo, hi := start[b], start[b+1]; hi-lo > 1 {
+			msdInts(s, x[lo:hi], buf[lo:hi], shift-8)
+		}
+	}
+}
+
+// RadixSortStringsLSD sorts strings in ascending byte order, one byte
+// position at a time from the end of the longest string. Shorter strings
+// sort before longer ones that share their prefix. It is stable and runs
+// in O(n * longest length), which suits short keys of similar length.
+func RadixSortStringsLSD(x []string) Stats {
+	var stats Stats
+	if len(x) < 2 {
+		return stats
+	}
+	longest := 0
+	for _, v := range x {
+		longest = max(longest, len(v))
+	}
+	stats.Allocations++
+	stats.AllocBytes += len(x) * sizeOf[string]()
+	src, dst := x, make([]string, len(x))
+	for pos := longest - 1; pos >= 0; pos-- {
+		// Bucket 0 holds strings too short to have a byte at pos.
+		var count [radixBuckets + 2]int
+		for _, v := range src {
+			count[charAt(v, pos)+1]++
+		}
+		for i := 1; i <= radixBuckets+1; i++ {
+			count[i] += count[i-1]
+		}
+		for _, v := range src {
+			c := charAt(v, pos)
+			dst[count[c]] = v
+			count[c]++
+		}
+		stats.Writes += len(src)
+		src, dst = dst, src
+	}
+	if &src[0] != &x[0] {
+		copy(x, src)
+		stats.Writes += len(x)
+	}
+	return stats
+}
+
+// RadixSortStringsMSD sorts strings in ascending byte order, one byte
+// position at a time from the start, recursing into each bucket. It only
+// looks at as many bytes as needed to tell strings apart, so it suits
+// long keys with short distinguishing prefixes. It is stable.
+func RadixSortStringsMSD(x []string) Stats {
+	s := newSorter(func(a, b string) int {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	})
+	if len(x) > 1 {
+		buf := s.scratch(len(x), sizeOf[string]())
+		msdStrings(s, x, buf, 0)
+	}
+	return s.stats
+}
+
+func msdStrings(s *sorter[string], x, buf []string, pos int) {
+	if len(x) <= insertionThreshold {
+		s.insertionSort(x)
+		return
+	}
+	var count [radixBuckets + 2]int
+	for _, v := range x {
+		count[charAt(v, pos)+1]++
+	}
+	if count[1] == len(x) {
+		return // Every string has ended
+	}
+	for i := 1; i <= radixBuckets+1; i++ {
+		count[i] += count[i-1]
+	}
+	start := count
+	for _, v := range x {
+		c := charAt(v, pos)
+		buf[count[c]] = v
+		count[c]++
+	}
+	copy(x, buf[:len(x)])
+	s.stats.Writes += 2 * len(x)
+	// Bucket 0 holds strings that ended at pos; they are already equal.
+	for c := 1; c <= radixBuckets; c++ {
+		if lo, hi := start[c], start[c+1]; hi-lo > 1 {
+			msdStrings(s, x[lo:hi], buf[lo:hi], pos+1)
+		}
+	}
+}
+
+// charAt returns the byte of s at pos plus one, or 0 past the end, so
+// shorter strings sort first.
+func charAt(s string, pos int) int {
+	if pos < len(s) {
+		return int(s[pos]) + 1
+	}
+	return 0
+}
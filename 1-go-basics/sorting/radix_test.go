@@ -0,0 +1,89 @@
+package sorting
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// checkRadix sorts a copy of x with both radix sorts and compares the
+// results with slices.Sort.
+func checkRadix[T Integer](t *testing.T, x []T) {
+	t.Helper()
+	want := slices.Clone(x)
+	slices.Sort(want)
+	for _, sort := range []struct {
+		name string
+		fn   func([]T) Stats
+	}{
+		{"LSD", RadixSortLSD[T]},
+		{"MSD", RadixSortMSD[T]},
+	} {
+		got := slices.Clone(x)
+		sort.fn(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("RadixSort%s(%v) = %v, want %v", sort.name, x, got, want)
+		}
+	}
+}
+
+// randomInts returns n values of T built from random bits, so they cover
+// negative and positive values across T's whole range.
+func randomInts[T Integer](r *rand.Rand, n int) []T {
+	x := make([]T, n)
+	for i := range x {
+		x[i] = T(r.Uint64())
+	}
+	return x
+}
+
+func TestRadixSortSigned(t *testing.T) {
+	t.Run("int8", func(t *testing.T) {
+		checkRadix(t, []int8{3, -1, 2, -5, 0})
+		checkRadix(t, []int8{math.MaxInt8, math.MinInt8, -1, 0, 1})
+	})
+	t.Run("int16", func(t *testing.T) {
+		checkRadix(t, []int16{3, -1, 2, -5, 0})
+		checkRadix(t, []int16{math.MaxInt16, math.MinInt16, -1, 0, 1})
+	})
+	t.Run("int32", func(t *testing.T) {
+		checkRadix(t, []int32{3, -1, 2, -5, 0})
+		checkRadix(t, []int32{math.MaxInt32, math.MinInt32, -1, 0, 1})
+	})
+	t.Run("int64", func(t *testing.T) {
+		checkRadix(t, []int64{3, -1, 2, -5, 0})
+		checkRadix(t, []int64{math.MaxInt64, math.MinInt64, -1, 0, 1})
+	})
+	t.Run("int", func(t *testing.T) {
+		checkRadix(t, []int{3, -1, 2, -5, 0})
+		checkRadix(t, []int{math.MaxInt, math.MinInt, -1, 0, 1})
+	})
+}
+
+func TestRadixSortUnsigned(t *testing.T) {
+	checkRadix(t, []uint8{3, 255, 0, 128, 127})
+	checkRadix(t, []uint16{3, math.MaxUint16, 0, 1 << 15})
+	checkRadix(t, []uint32{3, math.MaxUint32, 0, 1 << 31})
+	checkRadix(t, []uint64{3, math.MaxUint64, 0, 1 << 63})
+}
+
+func TestRadixSortEdgeCases(t *testing.T) {
+	checkRadix(t, []int32(nil))
+	checkRadix(t, []int32{-7})
+	checkRadix(t, []int32{-2, -2, -2, -2})
+}
+
+// TestRadixSortRandom uses inputs above insertionThreshold so that
+// RadixSortMSD buckets by byte instead of falling back to comparisons.
+func TestRadixSortRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{10, 100, 1000} {
+		checkRadix(t, randomInts[int8](r, n))
+		checkRadix(t, randomInts[int16](r, n))
+		checkRadix(t, randomInts[int32](r, n))
+		checkRadix(t, randomInts[int64](r, n))
+		checkRadix(t, randomInts[uint16](r, n))
+		checkRadix(t, randomInts[uint64](r, n))
+	}
+}
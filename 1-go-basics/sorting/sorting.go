@@ -0,0 +1,341 @@
+// Package sorting provides generic sorting algorithms that report how much
+// work they did, so algorithms can be compared on real data.
+//
+// Every comparison sort comes in two forms, mirroring slices.Sort and
+// slices.SortFunc: one for cmp.Ordered element types and a Func variant
+// taking a comparator that returns a negative number, zero or a positive
+// number. All of them sort in place and return Stats.
+package sorting
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Stats records the work done by a single sort call.
+type Stats struct {
+	Comparisons int // calls to the comparator
+	Swaps       int // exchanges of two elements
+	Writes      int // single element writes, e.g. merges and insertion shifts
+	Allocations int // scratch buffers allocated
+	AllocBytes  int // approximate size of the scratch buffers
+}
+
+// String formats the counters on one line.
+func (s Stats) String() string {
+	return fmt.Sprintf("comparisons=%d swaps=%d writes=%d allocs=%d (%d B)",
+		s.Comparisons, s.Swaps, s.Writes, s.Allocations, s.AllocBytes)
+}
+
+// sorter carries the comparator and counters through an algorithm.
+type sorter[T any] struct {
+	cmp   func(a, b T) int
+	stats Stats
+}
+
+func newSorter[T any](cmp func(a, b T) int) *sorter[T] {
+	return &sorter[T]{cmp: cmp}
+}
+
+func (s *sorter[T]) compare(a, b T) int {
+	s.stats.Comparisons++
+	return s.cmp(a, b)
+}
+
+func (s *sorter[T]) less(a, b T) bool {
+	return s.compare(a, b) < 0
+}
+
+func (s *sorter[T]) swap(x []T, i, j int) {
+	s.stats.Swaps++
+	x[i], x[j] = x[j], x[i]
+}
+
+// scratch allocates a buffer of n elements and records the allocation.
+func (s *sorter[T]) scratch(n int, elemSize int) []T {
+	s.stats.Allocations++
+	s.stats.AllocBytes += n * elemSize
+	return make([]T, n)
+}
+
+// ======================================================
+// Insertion Sort
+// ======================================================
+
+// InsertionSort sorts x in ascending order. It is stable and O(n^2), but
+// fastest on tiny or nearly sorted inputs.
+func InsertionSort[T cmp.Ordered](x []T) Stats {
+	return InsertionSortFunc(x, cmp.Compare[T])
+}
+
+// InsertionSortFunc sorts x in the order defined by cmp using insertion sort.
+func InsertionSortFunc[T any](x []T, cmp func(a, b T) int) Stats {
+	s := newSorter(cmp)
+	s.insertionSort(x)
+	return s.stats
+}
+
+func (s *sorter[T]) insertionSort(x []T) {
+	for i := 1; i < len(x); i++ {
+		v := x[i]
+		j := i
+		for ; j > 0 && s.less(v, x[j-1]); j-- {
+			x[j] = x[j-1]
+			s.stats.Writes++
+		}
+		if j != i {
+			x[j] = v
+			s.stats.Writes++
+		}
+	}
+}
+
+// ======================================================
+// Merge Sort
+// ======================================================
+
+// MergeSort sorts x in ascending order. It is stable, O(n log n) and uses
+// one scratch buffer of len(x)/2 elements.
+func MergeSort[T cmp.Ordered](x []T) Stats {
+	return MergeSortFunc(x, cmp.Compare[T])
+}
+
+// MergeSortFunc sorts x in the order defined by cmp using merge sort.
+func MergeSortFunc[T any](x []T, cmp func(a, b T) int) Stats {
+	s := newSorter(cmp)
+	if len(x) > 1 {
+		buf := s.scratch(len(x)/2, sizeOf[T]())
+		s.mergeSort(x, buf)
+	}
+	return s.stats
+}
+
+func (s *sorter[T]) mergeSort(x, buf []T) {
+	if len(x) < 2 {
+		return
+	}
+	mid := len(x) / 2
+	s.mergeSort(x[:mid], buf)
+	s.mergeSort(x[mid:], buf)
+	s.merge(x, mid, buf)
+}
+
+// merge merges the sorted runs x[:mid] and x[mid:] using buf, which must
+// hold at least mid elements. Only the left run is copied out.
+func (s *sorter[T]) merge(x []T, mid int, buf []T) {
+	if mid == 0 || mid == len(x) || !s.less(x[mid], x[mid-1]) {
+		return // Already in order
+	}
+	left := buf[:mid]
+	copy(left, x[:mid])
+	s.stats.Writes += mid
+	i, j, k := 0, mid, 0
+	for i < len(left) && j < len(x) {
+		// Take from the left run on ties to keep the sort stable.
+		if s.less(x[j], left[i]) {
+			x[k] = x[j]
+			j++
+		} else {
+			x[k] = left[i]
+			i++
+		}
+		k++
+		s.stats.Writes++
+	}
+	n := copy(x[k:], left[i:])
+	s.stats.Writes += n
+}
+
+// ======================================================
+// Quicksort (Three-Way Partitioning)
+// ======================================================
+
+// insertionThreshold is the size below which quicksort and the hybrid
+// sort switch to insertion sort.
+const insertionThreshold = 12
+
+// QuickSort sorts x in ascending order. It uses three-way partitioning,
+// so inputs with many equal keys stay O(n log n). It is not stable and
+// needs no scratch buffer.
+func QuickSort[T cmp.Ordered](x []T) Stats {
+	return QuickSortFunc(x, cmp.Compare[T])
+}
+
+// QuickSortFunc sorts x in the order defined by cmp using quicksort.
+func QuickSortFunc[T any](x []T, cmp func(a, b T) int) Stats {
+	s := newSorter(cmp)
+	s.quickSort(x)
+	return s.stats
+}
+
+func (s *sorter[T]) quickSort(x []T) {
+	for len(x) > insertionThreshold {
+		s.medianOfThree(x)
+		lt, gt := s.partition3(x)
+		// Recurse into the smaller side and loop on the larger one,
+		// which bounds the stack depth to O(log n).
+		if lt < len(x)-gt {
+			s.quickSort(x[:lt])
+			x = x[gt:]
+		} else {
+			s.quickSort(x[gt:])
+			x = x[:lt]
+		}
+	}
+	s.insertionSort(x)
+}
+
+// medianOfThree moves the median of the first, middle and last elements
+// to x[0] to serve as the pivot.
+func (s *sorter[T]) medianOfThree(x []T) {
+	a, b, c := 0, len(x)/2, len(x)-1
+	if s.less(x[b], x[a]) {
+		a, b = b, a
+	}
+	if s.less(x[c], x[b]) {
+		b = c
+		if s.less(x[b], x[a]) {
+			b = a
+		}
+	}
+	if b != 0 {
+		s.swap(x, 0, b)
+	}
+}
+
+// partition3 partitions x around the pivot x[0] into < pivot, == pivot
+// and > pivot (Dijkstra's Dutch national flag). It returns lt and gt such
+// that x[lt:gt] holds the elements equal to the pivot.
+func (s *sorter[T]) partition3(x []T) (lt, gt int) {
+	pivot := x[0]
+	lt, i, gt := 0, 1, len(x)
+	for i < gt {
+		switch c := s.compare(x[i], pivot); {
+		case c < 0:
+			s.swap(x, lt, i)
+			lt++
+			i++
+		case c > 0:
+			gt--
+			s.swap(x, i, gt)
+		default:
+			i++
+		}
+	}
+	return lt, gt
+}
+
+// ======================================================
+// Heapsort
+// ======================================================
+
+// HeapSort sorts x in ascending order. It is O(n log n) in the worst
+// case, needs no scratch buffer and is not stable.
+func HeapSort[T cmp.Ordered](x []T) Stats {
+	return HeapSortFunc(x, cmp.Compare[T])
+}
+
+// HeapSortFunc sorts x in the order defined by cmp using heapsort.
+func HeapSortFunc[T any](x []T, cmp func(a, b T) int) Stats {
+	s := newSorter(cmp)
+	for i := len(x)/2 - 1; i >= 0; i-- {
+		s.siftDown(x, i, len(x))
+	}
+	for end := len(x) - 1; end > 0; end-- {
+		s.swap(x, 0, end)
+		s.siftDown(x, 0, end)
+	}
+	return s.stats
+}
+
+// siftDown restores the max-heap property for the subtree at root,
+// considering only x[:n].
+func (s *sorter[T]) siftDown(x []T, root, n int) {
+	for {
+		child := 2*root + 1
+		if child >= n {
+			return
+		}
+		if child+1 < n && s.less(x[child], x[child+1]) {
+			child++
+		}
+		if !s.less(x[root], x[child]) {
+			return
+		}
+		s.swap(x, root, child)
+		root = child
+	}
+}
+
+// ======================================================
+// Hybrid Stable Sort
+// ======================================================
+
+// HybridSort sorts x in ascending order. It is stable: short runs are
+// sorted with insertion sort and then merged bottom-up, skipping merges
+// of runs that are already in order, so sorted and nearly sorted input
+// costs close to O(n).
+func HybridSort[T cmp.Ordered](x []T) Stats {
+	return HybridSortFunc(x, cmp.Compare[T])
+}
+
+// HybridSortFunc sorts x in the order defined by cmp using the hybrid sort.
+func HybridSortFunc[T any](x []T, cmp func(a, b T) int) Stats {
+	s := newSorter(cmp)
+	const run = insertionThreshold * 2
+	for lo := 0; lo < len(x); lo += run {
+		s.insertionSort(x[lo:min(lo+run, len(x))])
+	}
+	if len(x) <= run {
+		return s.stats
+	}
+	// The widest left run merged is the largest width below len(x).
+	widest := run
+	for widest*2 < len(x) {
+		widest *= 2
+	}
+	buf := s.scratch(widest, sizeOf[T]())
+	for width := run; width < len(x); width *= 2 {
+		for lo := 0; lo+width < len(x); lo += 2 * width {
+			s.merge(x[lo:min(lo+2*width, len(x))], width, buf)
+		}
+	}
+	return s.stats
+}
+
+// ======================================================
+// Comparing Algorithms
+// ======================================================
+
+// Result is the outcome of running one algorithm in Compare.
+type Result struct {
+	Algorithm string
+	Stats     Stats
+	Duration  time.Duration
+}
+
+// Compare sorts a copy of data with each comparison sort and reports the
+// work and wall time of each, to help pick an algorithm for a real data
+// distribution. data itself is left untouched.
+func Compare[T cmp.Ordered](data []T) []Result {
+	algorithms := []struct {
+		name string
+		sort func([]T) Stats
+	}{
+		{"insertion", InsertionSort[T]},
+		{"merge", MergeSort[T]},
+		{"quick", QuickSort[T]},
+		{"heap", HeapSort[T]},
+		{"hybrid", HybridSort[T]},
+	}
+	results := make([]Result, 0, len(algorithms))
+	for _, a := range algorithms {
+		x := slices.Clone(data)
+		start := time.Now()
+		stats := a.sort(x)
+		results = append(results, Result{Algorithm: a.name, Stats: stats, Duration: time.Since(start)})
+	}
+	return results
+}
@@ -0,0 +1,102 @@
+package visualize
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	dotHighlightNode = `style=filled, fillcolor="#ffd966"`
+	dotHighlightEdge = `color="#cc0000", penwidth=2`
+)
+
+// dotQuote returns s as a double-quoted DOT string.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+func dotNode(sb *strings.Builder, id, label string, highlighted bool) {
+	fmt.Fprintf(sb, "\t%s [label=%s", id, dotQuote(label))
+	if highlighted {
+		sb.WriteString(", " + dotHighlightNode)
+	}
+	sb.WriteString("];\n")
+}
+
+func dotEdge(sb *strings.Builder, from, to string, highlighted bool) {
+	fmt.Fprintf(sb, "\t%s -> %s", from, to)
+	if highlighted {
+		sb.WriteString(" [" + dotHighlightEdge + "]")
+	}
+	sb.WriteString(";\n")
+}
+
+// treeDOT writes nodes in pre-order. A node with a single child gets an
+// invisible sibling for the missing one, so dot still draws left children
+// to the left and right children to the right. A node reached twice is
+// drawn once, with a dashed back edge that does not affect the layout.
+func treeDOT(root *treeNode, s settings) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", dotQuote(s.name))
+	sb.WriteString("\tgraph [ordering=out];\n")
+	next := 0
+	ids := make(map[*treeNode]string)
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		id := fmt.Sprintf("n%d", next)
+		next++
+		ids[n] = id
+		dotNode(&sb, id, n.label, s.highlight[n.key])
+		for _, child := range []*treeNode{n.left, n.right} {
+			if child != nil && child.backTo != nil {
+				fmt.Fprintf(&sb, "\t%s -> %s [style=dashed, constraint=false", id, ids[child.backTo])
+				if s.highlight[n.key] && s.highlight[child.key] {
+					sb.WriteString(", " + dotHighlightEdge)
+				}
+				sb.WriteString("];\n")
+				continue
+			}
+			if child == nil {
+				if n.left != nil || n.right != nil {
+					hidden := fmt.Sprintf("n%d", next)
+					next++
+					fmt.Fprintf(&sb, "\t%s [style=invis];\n\t%s -> %s [style=invis];\n", hidden, id, hidden)
+				}
+				continue
+			}
+			dotEdge(&sb, id, fmt.Sprintf("n%d", next), s.highlight[n.key] && s.highlight[child.key])
+			walk(child)
+		}
+	}
+	if root != nil {
+		walk(root)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// listDOT lays the list out left to right and ends it with a nil point,
+// or with an edge back to the start of the cycle.
+func listDOT(l list, s settings) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", dotQuote(s.name))
+	sb.WriteString("\trankdir=LR;\n\tnode [shape=box];\n")
+	for i, lbl := range l.labels {
+		dotNode(&sb, fmt.Sprintf("n%d", i), lbl, s.highlight[l.keys[i]])
+	}
+	for i := 1; i < len(l.keys); i++ {
+		dotEdge(&sb, fmt.Sprintf("n%d", i-1), fmt.Sprintf("n%d", i), s.highlight[l.keys[i-1]] && s.highlight[l.keys[i]])
+	}
+	last := len(l.keys) - 1
+	switch {
+	case l.cycleTo >= 0:
+		highlighted := s.highlight[l.keys[last]] && s.highlight[l.keys[l.cycleTo]]
+		dotEdge(&sb, fmt.Sprintf("n%d", last), fmt.Sprintf("n%d", l.cycleTo), highlighted)
+	case last >= 0:
+		sb.WriteString("\tnil [shape=point];\n")
+		dotEdge(&sb, fmt.Sprintf("n%d", last), "nil", false)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
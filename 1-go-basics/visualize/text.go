@@ -0,0 +1,90 @@
+package visualize
+
+import "strings"
+
+// glyphs are the pieces text diagrams are drawn with.
+type glyphs struct {
+	branch, lastBranch string // Before a child that has / has no later sibling
+	pipe, space        string // Indent below a child that has / has no later sibling
+	arrow, nilMark     string
+}
+
+var (
+	unicodeGlyphs = glyphs{"├── ", "└── ", "│   ", "    ", " → ", "∅"}
+	asciiGlyphs   = glyphs{"|-- ", "`-- ", "|   ", "    ", " -> ", "nil"}
+)
+
+func (s settings) glyphs() glyphs {
+	if s.ascii {
+		return asciiGlyphs
+	}
+	return unicodeGlyphs
+}
+
+// mark wraps a highlighted label in asterisks.
+func (s settings) mark(key any, label string) string {
+	if s.highlight[key] {
+		return "*" + label + "*"
+	}
+	return label
+}
+
+// treeText draws the root on the first line and each child below its
+// parent, indented and tagged L or R. A node reached twice is shown the
+// second time as "(back to label)" and not expanded again:
+//
+//	50
+//	├── L: 30
+//	│   └── R: 40
+//	└── R: 70
+func treeText(root *treeNode, s settings) string {
+	if root == nil {
+		return "(empty)\n"
+	}
+	g := s.glyphs()
+	var sb strings.Builder
+	sb.WriteString(s.mark(root.key, root.label) + "\n")
+	var walk func(n *treeNode, indent string)
+	walk = func(n *treeNode, indent string) {
+		children := []struct {
+			side string
+			node *treeNode
+		}{{"L: ", n.left}, {"R: ", n.right}}
+		if n.right == nil {
+			children = children[:1]
+		}
+		if n.left == nil {
+			children = children[1:]
+		}
+		for i, child := range children {
+			branch, below := g.branch, g.pipe
+			if i == len(children)-1 {
+				branch, below = g.lastBranch, g.space
+			}
+			if child.node.backTo != nil {
+				sb.WriteString(indent + branch + child.side + "(back to " + s.mark(child.node.key, child.node.label) + ")\n")
+				continue
+			}
+			sb.WriteString(indent + branch + child.side + s.mark(child.node.key, child.node.label) + "\n")
+			walk(child.node, indent+below)
+		}
+	}
+	walk(root, "")
+	return sb.String()
+}
+
+// listText draws the list on one line. A cycle ends the line with the
+// node it loops back to instead of nil.
+func listText(l list, s settings) string {
+	g := s.glyphs()
+	var sb strings.Builder
+	for i, lbl := range l.labels {
+		sb.WriteString(s.mark(l.keys[i], lbl) + g.arrow)
+	}
+	if l.cycleTo >= 0 {
+		sb.WriteString("(cycle to " + l.labels[l.cycleTo] + ")")
+	} else {
+		sb.WriteString(g.nilMark)
+	}
+	return sb.String()
+}
@@ -0,0 +1,201 @@
+// Package visualize renders the structures in package datastructures for
+// debugging, code review and teaching. Each structure can be drawn as a
+// Graphviz DOT graph, to be piped into `dot -Tsvg`, or as a text diagram
+// to print in the terminal.
+//
+// Node labels come from the stored values: a value that implements
+// fmt.Stringer is labelled with its String method, anything else with %v.
+// Options.Highlight marks nodes, such as the path returned by
+// BinarySearchTree.SearchPath, in both output formats.
+package visualize
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/abtin81badie/GoLangEssentials/datastructures"
+)
+
+// Options configures rendering. A nil *Options uses the defaults.
+type Options struct {
+	// Highlight lists the nodes to emphasize, given as the structure's own
+	// node pointers: *datastructures.TreeNode, *datastructures.ListNode[T]
+	// or *datastructures.PriorityQueueItem. An edge is emphasized when
+	// both of its ends are, so passing a search path highlights the path;
+	// Path converts one. A slice in the list stands for its elements, and
+	// other values that cannot be map keys are ignored.
+	Highlight []any
+	// ASCII limits text diagrams to ASCII instead of Unicode box drawing.
+	ASCII bool
+	// Name is the DOT graph name. Each structure has its own default.
+	Name string
+}
+
+// treeNode is a binary tree in a form both renderers understand. If
+// backTo is not nil, the original node was reached a second time, through
+// a cycle or a shared subtree; it is drawn as a link back to backTo, the
+// place it was first drawn, and has no children.
+type treeNode struct {
+	key         any // The original node, for highlighting
+	label       string
+	left, right *treeNode
+	backTo      *treeNode
+}
+
+// list is a linked list in a form both renderers understand. If cycleTo
+// is not -1, the last node links back to nodes[cycleTo].
+type list struct {
+	keys    []any
+	labels  []string
+	cycleTo int
+}
+
+// settings are Options with defaults applied and Highlight as a set.
+type settings struct {
+	highlight map[any]bool
+	ascii     bool
+	name      string
+}
+
+func newSettings(opts *Options, defaultName string) settings {
+	if opts == nil {
+		opts = &Options{}
+	}
+	s := settings{highlight: make(map[any]bool, len(opts.Highlight)), ascii: opts.ASCII, name: opts.Name}
+	for _, node := range opts.Highlight {
+		s.addHighlight(reflect.ValueOf(node))
+	}
+	if s.name == "" {
+		s.name = defaultName
+	}
+	return s
+}
+
+// addHighlight adds v to the highlight set, or each element of v if it
+// is a slice or array. Values that would panic as map keys are skipped.
+func (s settings) addHighlight(v reflect.Value) {
+	switch {
+	case !v.IsValid():
+	case v.Kind() == reflect.Interface:
+		s.addHighlight(v.Elem())
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			s.addHighlight(v.Index(i))
+		}
+	case v.Comparable():
+		s.highlight[v.Interface()] = true
+	}
+}
+
+// Path returns nodes as a []any for Options.Highlight, for example the
+// path returned by BinarySearchTree.SearchPath.
+func Path[N any](nodes []*N) []any {
+	result := make([]any, len(nodes))
+	for i, node := range nodes {
+		result[i] = node
+	}
+	return result
+}
+
+// label returns the text for a value. fmt already prefers String for
+// values that implement fmt.Stringer, and recovers if it panics on a nil
+// pointer. Newlines are flattened so a label stays on one line.
+func label(v any) string {
+	return strings.ReplaceAll(fmt.Sprint(v), "\n", " ")
+}
+
+// ======================================================
+// Binary Search Tree
+// ======================================================
+
+// BSTDOT renders bst as a DOT graph, including a back edge for any node
+// the Left and Right links reach twice.
+func BSTDOT(bst *datastructures.BinarySearchTree, opts *Options) string {
+	return treeDOT(bstTree(bst.Root), newSettings(opts, "BST"))
+}
+
+// BSTText renders bst as a text diagram with the root on the first line.
+func BSTText(bst *datastructures.BinarySearchTree, opts *Options) string {
+	return treeText(bstTree(bst.Root), newSettings(opts, "BST"))
+}
+
+func bstTree(root *datastructures.TreeNode) *treeNode {
+	seen := make(map[*datastructures.TreeNode]*treeNode)
+	var build func(node *datastructures.TreeNode) *treeNode
+	build = func(node *datastructures.TreeNode) *treeNode {
+		if node == nil {
+			return nil
+		}
+		if first, ok := seen[node]; ok {
+			return &treeNode{key: node, label: first.label, backTo: first}
+		}
+		n := &treeNode{key: node, label: label(node.Data)}
+		seen[node] = n
+		n.left, n.right = build(node.Left), build(node.Right)
+		return n
+	}
+	return build(root)
+}
+
+// ======================================================
+// Linked List
+// ======================================================
+
+// ListDOT renders l as a DOT graph, including a cycle if the Next links
+// were changed to form one.
+func ListDOT[T any](l *datastructures.LinkedList[T], opts *Options) string {
+	return listDOT(linkedList(l), newSettings(opts, "LinkedList"))
+}
+
+// ListText renders l on one line, like "a → b → ∅".
+func ListText[T any](l *datastructures.LinkedList[T], opts *Options) string {
+	return listText(linkedList(l), newSettings(opts, "LinkedList"))
+}
+
+func linkedList[T any](l *datastructures.LinkedList[T]) list {
+	result := list{cycleTo: -1}
+	seen := make(map[*datastructures.ListNode[T]]int)
+	for node := l.Head; node != nil; node = node.Next {
+		if i, ok := seen[node]; ok {
+			result.cycleTo = i
+			break
+		}
+		seen[node] = len(result.keys)
+		result.keys = append(result.keys, node)
+		result.labels = append(result.labels, label(node.Data))
+	}
+	return result
+}
+
+// ======================================================
+// Priority Queue
+// ======================================================
+
+// HeapDOT renders pq as the binary tree its heap order describes: the
+// children of item i are items 2i+1 and 2i+2.
+func HeapDOT(pq datastructures.PriorityQueue, opts *Options) string {
+	return treeDOT(heapTree(pq, 0), newSettings(opts, "PriorityQueue"))
+}
+
+// HeapText renders pq as a text diagram of its heap tree.
+func HeapText(pq datastructures.PriorityQueue, opts *Options) string {
+	return treeText(heapTree(pq, 0), newSettings(opts, "PriorityQueue"))
+}
+
+func heapTree(pq datastructures.PriorityQueue, i int) *treeNode {
+	if i >= len(pq) {
+		return nil
+	}
+	item := pq[i]
+	if item == nil {
+		// Validate reports nil items; draw them rather than crash.
+		return &treeNode{label: "<nil>", left: heapTree(pq, 2*i+1), right: heapTree(pq, 2*i+2)}
+	}
+	return &treeNode{
+		key:   item,
+		label: fmt.Sprintf("%s (p=%d)", label(item.Value), item.Priority),
+		left:  heapTree(pq, 2*i+1),
+		right: heapTree(pq, 2*i+2),
+	}
+}
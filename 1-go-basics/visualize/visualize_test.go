@@ -0,0 +1,123 @@
+package visualize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abtin81badie/GoLangEssentials/datastructures"
+)
+
+func sampleBST() *datastructures.BinarySearchTree {
+	var bst datastructures.BinarySearchTree
+	for _, v := range []int{50, 30, 70, 40} {
+		bst.Insert(v)
+	}
+	return &bst
+}
+
+func TestBSTText(t *testing.T) {
+	want := "50\n├── L: 30\n│   └── R: 40\n└── R: 70\n"
+	if got := BSTText(sampleBST(), nil); got != want {
+		t.Fatalf("BSTText =\n%s\nwant\n%s", got, want)
+	}
+	wantASCII := "50\n|-- L: 30\n|   `-- R: 40\n`-- R: 70\n"
+	if got := BSTText(sampleBST(), &Options{ASCII: true}); got != wantASCII {
+		t.Fatalf("BSTText ASCII =\n%s\nwant\n%s", got, wantASCII)
+	}
+	if got := BSTText(&datastructures.BinarySearchTree{}, nil); got != "(empty)\n" {
+		t.Fatalf("BSTText of an empty tree = %q", got)
+	}
+}
+
+func TestBSTHighlight(t *testing.T) {
+	bst := sampleBST()
+	path, _ := bst.SearchPath(40)
+	for name, highlight := range map[string][]any{
+		"Path":           Path(path),
+		"slice in list":  {path},
+		"unhashable too": {path, []any{map[int]int{}}, func() {}},
+	} {
+		opts := &Options{Highlight: highlight}
+		if got := BSTText(bst, opts); !strings.Contains(got, "*50*") || !strings.Contains(got, "R: *40*") || strings.Contains(got, "*70*") {
+			t.Fatalf("%s: BSTText did not mark the path 50, 30, 40:\n%s", name, got)
+		}
+		if got := strings.Count(BSTDOT(bst, opts), "penwidth"); got != 2 {
+			t.Fatalf("%s: BSTDOT highlighted %d edges, want 2", name, got)
+		}
+	}
+}
+
+// TestBSTCycle corrupts the exported links into a cycle and a shared
+// subtree, which used to recurse until the stack overflowed.
+func TestBSTCycle(t *testing.T) {
+	bst := sampleBST()
+	thirty := bst.Root.Left
+	thirty.Right.Left = bst.Root  // 40 -> 50 closes a cycle
+	bst.Root.Right.Right = thirty // 70 -> 30 shares a subtree
+
+	text := BSTText(bst, nil)
+	want := "50\n├── L: 30\n│   └── R: 40\n│       └── L: (back to 50)\n└── R: 70\n    └── R: (back to 30)\n"
+	if text != want {
+		t.Fatalf("BSTText =\n%s\nwant\n%s", text, want)
+	}
+	dot := BSTDOT(bst, nil)
+	if got := strings.Count(dot, "style=dashed"); got != 2 {
+		t.Fatalf("BSTDOT has %d back edges, want 2:\n%s", got, dot)
+	}
+	if got := strings.Count(dot, "[label="); got != 4 {
+		t.Fatalf("BSTDOT drew %d nodes, want 4:\n%s", got, dot)
+	}
+	if !strings.Contains(dot, "n3 -> n0 [style=dashed") {
+		t.Fatalf("BSTDOT is missing the edge from 40 back to 50:\n%s", dot)
+	}
+}
+
+func TestListCycle(t *testing.T) {
+	var l datastructures.LinkedList[string]
+	for _, v := range []string{"a", "b", "c"} {
+		l.Append(v)
+	}
+	if got := ListText(&l, nil); got != "a → b → c → ∅" {
+		t.Fatalf("ListText = %q", got)
+	}
+	l.Head.Next.Next.Next = l.Head.Next
+	if got := ListText(&l, &Options{ASCII: true}); got != "a -> b -> c -> (cycle to b)" {
+		t.Fatalf("ListText with a cycle = %q", got)
+	}
+	if dot := ListDOT(&l, nil); !strings.Contains(dot, "n2 -> n1;") || strings.Contains(dot, "nil") {
+		t.Fatalf("ListDOT with a cycle:\n%s", dot)
+	}
+}
+
+func TestHeapText(t *testing.T) {
+	pq := datastructures.PriorityQueue{
+		{Value: "a", Priority: 1, Index: 0},
+		{Value: "b", Priority: 2, Index: 1},
+		{Value: "c", Priority: 3, Index: 2},
+	}
+	want := "a (p=1)\n├── L: b (p=2)\n└── R: c (p=3)\n"
+	if got := HeapText(pq, nil); got != want {
+		t.Fatalf("HeapText =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHeapNilItem(t *testing.T) {
+	pq := datastructures.PriorityQueue{
+		{Value: "a", Priority: 1, Index: 0},
+		nil,
+		{Value: "c", Priority: 3, Index: 2},
+	}
+	want := "a (p=1)\n├── L: <nil>\n└── R: c (p=3)\n"
+	if got := HeapText(pq, nil); got != want {
+		t.Fatalf("HeapText =\n%s\nwant\n%s", got, want)
+	}
+	if dot := HeapDOT(pq, nil); !strings.Contains(dot, `[label="<nil>"]`) {
+		t.Fatalf("HeapDOT has no <nil> node:\n%s", dot)
+	}
+}
+
+func TestDOTQuote(t *testing.T) {
+	if got := dotQuote(`say "hi" \ bye`); got != `"say \"hi\" \\ bye"` {
+		t.Fatalf("dotQuote = %s", got)
+	}
+}